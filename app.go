@@ -3,31 +3,29 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // App struct
 type App struct {
-	ctx                   context.Context
-	audioService          *AudioService
-	transcriptionService  *TranscriptionService
-	fileService           *FileService
-	costTrackingService   *CostTrackingService
-	folderMonitorService  *FolderMonitorService
-	convexClient          *ConvexClient
-	parserService         *ParserService
+	ctx                  context.Context
+	audioService         *AudioService
+	transcriptionService *TranscriptionService
+	fileService          *FileService
+	costTrackingService  *CostTrackingService
+	auditService         *AuditService
+	hotkeyService        *HotkeyService
 }
 
 // NewApp creates a new App application struct
-func NewApp(audioService *AudioService, transcriptionService *TranscriptionService, fileService *FileService, costTrackingService *CostTrackingService, convexClient *ConvexClient) *App {
+func NewApp(audioService *AudioService, transcriptionService *TranscriptionService, fileService *FileService, costTrackingService *CostTrackingService) *App {
 	app := &App{
 		audioService:         audioService,
 		transcriptionService: transcriptionService,
 		fileService:          fileService,
 		costTrackingService:  costTrackingService,
-		folderMonitorService: NewFolderMonitorService(),
-		convexClient:         convexClient,
 	}
-	app.parserService = NewParserService(app, convexClient)
+	app.hotkeyService = NewHotkeyService(app)
 	return app
 }
 
@@ -36,16 +34,16 @@ func NewApp(audioService *AudioService, transcriptionService *TranscriptionServi
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 	a.audioService.SetContext(ctx)
-	a.folderMonitorService.SetContext(ctx, a, a.convexClient)
+	a.hotkeyService.Start()
 
 	devices, err := a.audioService.ListDevices()
 	if err != nil {
-		fmt.Printf("Error listing devices: %v\n", err)
+		audioLog.Debugf("Error listing devices: %v\n", err)
 		return
 	}
 
 	for _, device := range devices {
-		fmt.Printf("Device: %s\n", device)
+		audioLog.Debugf("Device: %s\n", device)
 	}
 }
 
@@ -66,6 +64,11 @@ func (a *App) StopRecording() error {
 	return a.audioService.StopRecording()
 }
 
+// IsRecording returns whether a recording is currently in progress
+func (a *App) IsRecording() bool {
+	return a.audioService.IsRecording()
+}
+
 // GetInputDevices returns a list of available audio input devices
 func (a *App) GetInputDevices() ([]string, error) {
 	return a.audioService.ListInputDevices()
@@ -76,75 +79,65 @@ func (a *App) GetDefaultInputDevice() (string, error) {
 	return a.audioService.GetDefaultInputDeviceName()
 }
 
-// Cost tracking methods for the frontend
-
-// GetCostSummary returns a summary of costs
-func (a *App) GetCostSummary() map[string]interface{} {
-	return a.costTrackingService.GetCostSummary()
-}
-
-// GetDailyCosts returns daily cost breakdown for the last N days
-func (a *App) GetDailyCosts(days int) []DailyCost {
-	return a.costTrackingService.GetDailyCosts(days)
-}
-
-// EstimateCost estimates cost for a given duration without recording it
-func (a *App) EstimateCost(durationSeconds float64) float64 {
-	return a.costTrackingService.EstimateCost(durationSeconds)
+// GetCaptureConfig returns the currently configured audio capture parameters
+func (a *App) GetCaptureConfig() CaptureConfig {
+	return a.audioService.GetCaptureConfig()
 }
 
-// ResetSessionCost resets the session cost counter
-func (a *App) ResetSessionCost() {
-	a.costTrackingService.ResetSessionCost()
+// SetCaptureConfig updates the audio capture parameters for future recordings
+func (a *App) SetCaptureConfig(cfg CaptureConfig) error {
+	return a.audioService.SetCaptureConfig(cfg)
 }
 
-// Folder Monitor Methods
+// Hotkey / MIDI trigger methods for the frontend
 
-func (a *App) SetSelectedFolder(folderId string, path string) error {
-	return a.folderMonitorService.SetSelectedFolder(folderId, path)
+// ListMidiInputs returns the names of available MIDI input devices that
+// could be bound as a recording trigger.
+func (a *App) ListMidiInputs() ([]string, error) {
+	return a.hotkeyService.ListMidiInputs()
 }
 
-// SelectFolderToMonitor opens a folder picker and returns the path
-func (a *App) SelectFolderToMonitor() (string, error) {
-	return a.folderMonitorService.SelectFolder()
+// BindMidiTrigger binds a note-on from a MIDI device/note to a recording
+// action (start, stop, or toggle).
+func (a *App) BindMidiTrigger(deviceName string, note int, action string) error {
+	return a.hotkeyService.BindMidiTrigger(deviceName, note, action)
 }
 
-// ScanMonitoredFolder rescans the currently selected folder for files
-func (a *App) ScanMonitoredFolder() error {
-	return a.folderMonitorService.ScanFolder()
+// BindGlobalHotkey binds an OS-global accelerator (e.g. "CmdOrCtrl+Shift+R")
+// to a recording action (start, stop, or toggle).
+func (a *App) BindGlobalHotkey(accelerator string, action string) error {
+	return a.hotkeyService.BindGlobalHotkey(accelerator, action)
 }
 
-// StartFolderMonitoring starts monitoring the selected folder for new files
-func (a *App) StartFolderMonitoring() error {
-	return a.folderMonitorService.StartMonitoring()
+// SearchAuditLog returns recorded sessions between from and to matching
+// filter, transparently reading both the live and rotated audit segments.
+func (a *App) SearchAuditLog(from, to time.Time, filter AuditFilter) ([]AuditEvent, error) {
+	return a.auditService.SearchAuditLog(from, to, filter)
 }
 
-// StopFolderMonitoring stops monitoring the folder
-func (a *App) StopFolderMonitoring() error {
-	return a.folderMonitorService.StopMonitoring()
-}
+// Cost tracking methods for the frontend
 
-// ProcessAllFolderFiles processes all files in the folder
-func (a *App) ProcessAllFolderFiles() error {
-	return a.folderMonitorService.ProcessAllFiles()
+// GetCostSummary returns a summary of costs
+func (a *App) GetCostSummary() map[string]interface{} {
+	return a.costTrackingService.GetCostSummary()
 }
 
-// GetSelectedFolder returns the currently selected folder path
-func (a *App) GetSelectedFolder() string {
-	return a.folderMonitorService.GetSelectedFolder()
+// GetDailyCosts returns daily cost breakdown for the last N days
+func (a *App) GetDailyCosts(days int) []DailyCost {
+	return a.costTrackingService.GetDailyCosts(days)
 }
 
-// GetFolderFiles returns the list of files found in the folder (backward compatibility)
-func (a *App) GetFolderFiles() []FileInfo {
-	return a.folderMonitorService.GetFiles()
+// GetHourlyCosts returns hourly cost breakdown for the last N hours
+func (a *App) GetHourlyCosts(hours int) []DailyCost {
+	return a.costTrackingService.GetHourlyCosts(hours)
 }
 
-// GetProcessingQueue returns the current processing queue (backward compatibility)
-func (a *App) GetProcessingQueue() []FileInfo {
-	return a.folderMonitorService.GetProcessingQueue()
+// EstimateCost estimates cost for a given duration without recording it
+func (a *App) EstimateCost(durationSeconds float64) float64 {
+	return a.costTrackingService.EstimateCost(durationSeconds)
 }
 
-// IsMonitoringFolder returns whether folder monitoring is active
-func (a *App) IsMonitoringFolder() bool {
-	return a.folderMonitorService.IsMonitoring()
+// ResetSessionCost resets the session cost counter
+func (a *App) ResetSessionCost() {
+	a.costTrackingService.ResetSessionCost()
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -13,6 +14,7 @@ type CleanApp struct {
 	ctx                context.Context
 	voiceWorkerService *VoiceWorkerService
 	convexClient       *ConvexClient
+	metricsService     *MetricsService
 }
 
 // NewCleanApp creates a new clean App application struct
@@ -27,6 +29,7 @@ func NewCleanApp() *CleanApp {
 	return &CleanApp{
 		voiceWorkerService: voiceWorkerService,
 		convexClient:       convexClient,
+		metricsService:     NewMetricsService(),
 	}
 }
 
@@ -52,14 +55,25 @@ func (a *CleanApp) startup(ctx context.Context) {
 		log.Printf("Warning: could not reset stale jobs: %v", err)
 	}
 
+	// No-op unless METRICS_ADDR is set in the environment.
+	a.metricsService.Start()
+
 	log.Println("Voice Worker started successfully")
 }
 
+// shutdownTimeout bounds how long shutdown waits for in-flight jobs to
+// drain before returning control to Wails (or the CLI's own defer), on top
+// of whatever grace StopProcessing itself already gives them.
+const shutdownTimeout = 30 * time.Second
+
 // shutdown is called when the app is shutting down
 func (a *CleanApp) shutdown(ctx context.Context) {
 	if a.voiceWorkerService != nil {
-		a.voiceWorkerService.Stop()
+		stopCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+		defer cancel()
+		a.voiceWorkerService.Stop(stopCtx)
 	}
+	a.metricsService.Stop(ctx)
 }
 
 // Greet returns a greeting for the given name
@@ -184,6 +198,46 @@ func (a *CleanApp) RetryJob(jobId string) error {
 	return a.voiceWorkerService.jobQueue.RetryJob(jobId)
 }
 
+// GetJob returns a single job's full detail - attempt history, next
+// scheduled retry time, and last error - for a job detail view that needs
+// more than GetQueueStatus's aggregate counts.
+func (a *CleanApp) GetJob(jobId string) (JobDetail, error) {
+	return a.voiceWorkerService.jobQueue.GetJob(jobId)
+}
+
+// MigrateJobQueueToLocal switches job persistence from Convex to an
+// embedded local database, first copying every job and every file in
+// folderId so the local store can resolve jobs on its own. It returns the
+// number of jobs migrated. The job queue must have been created with the
+// default (Convex) backend; calling this after it's already local is a
+// no-op error.
+func (a *CleanApp) MigrateJobQueueToLocal(localPath, folderId string) (int, error) {
+	impl, ok := a.voiceWorkerService.jobQueue.(*JobQueueServiceImpl)
+	if !ok {
+		return 0, fmt.Errorf("job queue does not support local migration")
+	}
+	return impl.MigrateToLocalStore(localPath, folderId)
+}
+
+// SubmitSpec parses a declarative YAML pipeline spec and materializes its
+// first stage into queued jobs, returning how many were queued. Downstream
+// stages aren't queued here - they're chained automatically once each
+// upstream job's completedAt is set, replacing a flat ProcessAllFolderFiles
+// fan-out with a DAG that respects each stage's dependency on the last.
+func (a *CleanApp) SubmitSpec(path string) (int, error) {
+	spec, err := ParsePipelineSpec(path)
+	if err != nil {
+		return 0, err
+	}
+
+	impl, ok := a.voiceWorkerService.jobQueue.(*JobQueueServiceImpl)
+	if !ok {
+		return 0, fmt.Errorf("job queue does not support pipeline specs")
+	}
+
+	return impl.SubmitPipelineSpec(spec)
+}
+
 // === File Management Methods ===
 
 // ProcessAllFolderFiles queues parse jobs for all unprocessed files
@@ -261,6 +315,24 @@ func (a *CleanApp) EstimateCost(filePath, parserId string) (float64, error) {
 	return parser.EstimateCost(filePath)
 }
 
+// GetBudget returns the currently configured daily/monthly/session/
+// per-parser cost budget.
+func (a *CleanApp) GetBudget() BudgetConfig {
+	return a.voiceWorkerService.GetBudget()
+}
+
+// SetBudget updates the cost budget the job queue enforces before
+// dispatching a job - see JobQueueServiceImpl.checkJobBudget.
+func (a *CleanApp) SetBudget(budget BudgetConfig) error {
+	return a.voiceWorkerService.SetBudget(budget)
+}
+
+// DryRun reports what processing every file currently in the monitored
+// folder would cost, without queuing or running any jobs.
+func (a *CleanApp) DryRun() (DryRunReport, error) {
+	return a.voiceWorkerService.DryRun()
+}
+
 // === Audio Recording Methods (Legacy Support) ===
 
 // GetInputDevices returns a list of available audio input devices
@@ -343,3 +415,10 @@ func (a *CleanApp) GetProcessingQueue() ([]map[string]interface{}, error) {
 
 	return queue, nil
 }
+
+// GetRecentLogs returns buffered structured log entries for a log viewer to
+// render, oldest-first. level, parserId, and filePath each filter their
+// respective field when non-empty; pass "" to skip a filter.
+func (a *CleanApp) GetRecentLogs(level, parserId, filePath string) []StructuredLogEntry {
+	return appLog.Recent(level, parserId, filePath)
+}
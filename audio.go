@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-audio/audio"
@@ -16,6 +19,20 @@ import (
 
 const sampleRate = 44100
 
+// transcriptionModel mirrors the model TranscriptionService requests from
+// the Whisper API, recorded into the audit log alongside each session.
+const transcriptionModel = "whisper-1"
+
+var audioLog = GetFacility("audio")
+
+// VAD tuning. These are simple energy/zero-crossing thresholds, not a
+// statistical model - good enough to segment dictation-style speech.
+const (
+	vadEnergyThreshold    = 500.0 // RMS amplitude below this counts as silence
+	vadSilenceHangoverMs  = 800   // silence required after speech before a segment closes
+	vadMinSegmentDuration = 300 * time.Millisecond
+)
+
 // AudioService handles all audio recording functionality.
 type AudioService struct {
 	ctx                  context.Context
@@ -26,23 +43,56 @@ type AudioService struct {
 	transcriptionService *TranscriptionService
 	fileService          *FileService
 	costTrackingService  *CostTrackingService
+	auditService         *AuditService
 	devices              []*portaudio.DeviceInfo
+	currentDeviceName    string
+
+	// Live input level metering. levelChan decouples the PortAudio
+	// callback from event marshaling; only the callback goroutine writes
+	// recordingHadSound/lastLevelEmit, and only runLevelMeter reads them
+	// (via the samples it receives), so there's no shared-state race.
+	levelChan         chan levelSample
+	levelDone         chan struct{}
+	lastLevelEmit     time.Time
+	recordingHadSound bool
+
+	// VAD / streaming segmentation state. All of it is only ever touched
+	// from the PortAudio callback goroutine except segmentsMu-guarded
+	// fields, which are also read by StopRecording.
+	segmentBuffer bytes.Buffer
+	inSpeech      bool
+	silentSince   time.Time
+	segmentStart  time.Time
+	segmentsMu    sync.Mutex
+	segmentWG     sync.WaitGroup
+	segmentsText  []string
+
+	// Capture parameters and the values actually negotiated for the
+	// stream currently open (which may differ from captureConfig, e.g.
+	// if the device doesn't support the requested sample rate).
+	captureConfig    CaptureConfig
+	activeSampleRate float64
+	activeChannels   int
+	activeBitDepth   int
 }
 
 // NewAudioService creates a new AudioService.
-func NewAudioService(transcriptionService *TranscriptionService, fileService *FileService, costTrackingService *CostTrackingService) *AudioService {
-	return &AudioService{
+func NewAudioService(transcriptionService *TranscriptionService, fileService *FileService, costTrackingService *CostTrackingService, auditService *AuditService) *AudioService {
+	s := &AudioService{
 		transcriptionService: transcriptionService,
 		fileService:          fileService,
 		costTrackingService:  costTrackingService,
+		auditService:         auditService,
 	}
+	s.loadCaptureConfig()
+	return s
 }
 
 // Initialize initializes the audio service.
 func (s *AudioService) Initialize() {
-	fmt.Println("Initializing PortAudio...")
+	audioLog.Debugln("Initializing PortAudio...")
 	if err := portaudio.Initialize(); err != nil {
-		fmt.Printf("Error initializing PortAudio: %v\n", err)
+		audioLog.Debugf("Error initializing PortAudio: %v\n", err)
 	}
 }
 
@@ -50,13 +100,18 @@ func (s *AudioService) Initialize() {
 func (s *AudioService) Teardown(ctx context.Context) {
 	if s.isRecording {
 		if err := s.StopRecording(); err != nil {
-			fmt.Printf("Error stopping recording during teardown: %v\n", err)
+			audioLog.Debugf("Error stopping recording during teardown: %v\n", err)
 		}
 	}
 	if err := portaudio.Terminate(); err != nil {
-		fmt.Printf("Error terminating PortAudio: %v\n", err)
+		audioLog.Debugf("Error terminating PortAudio: %v\n", err)
 	}
-	fmt.Println("PortAudio terminated.")
+	audioLog.Debugln("PortAudio terminated.")
+}
+
+// IsRecording returns whether a recording is currently in progress.
+func (s *AudioService) IsRecording() bool {
+	return s.isRecording
 }
 
 // ListDevices lists the available audio input devices.
@@ -119,26 +174,94 @@ func (s *AudioService) StartRecording(deviceName string) error {
 		return fmt.Errorf("input device not found: %s. please select a valid device", deviceName)
 	}
 
-	fmt.Printf("Starting recording on device: %s\n", targetDevice.Name)
+	audioLog.Debugf("Starting recording on device: %s\n", targetDevice.Name)
 
-	buffer := make([]int16, 256)
+	cfg := s.captureConfig
+	channels := cfg.Channels
+	if channels != 1 && channels != 2 {
+		channels = 1
+	}
+	bitDepth := cfg.BitDepth
+	if bitDepth != 16 && bitDepth != 32 {
+		bitDepth = 16
+	}
+	framesPerBuffer := cfg.FramesPerBuffer
+	if framesPerBuffer <= 0 {
+		framesPerBuffer = 256
+	}
+
+	// Negotiate the closest supported sample rate. PortAudio doesn't
+	// expose a list of supported rates up front, so we try the requested
+	// rate and fall back to the device's default if opening fails.
+	requestedRate := cfg.SampleRate
+	if requestedRate <= 0 {
+		requestedRate = targetDevice.DefaultSampleRate
+	}
 
 	streamParameters := portaudio.StreamParameters{
 		Input: portaudio.StreamDeviceParameters{
 			Device:   targetDevice,
-			Channels: 1,
+			Channels: channels,
 			Latency:  targetDevice.DefaultLowInputLatency,
 		},
-		SampleRate:      sampleRate,
-		FramesPerBuffer: len(buffer),
+		SampleRate:      requestedRate,
+		FramesPerBuffer: framesPerBuffer,
+	}
+
+	var stream *portaudio.Stream
+	var err error
+
+	if bitDepth == 32 {
+		stream, err = portaudio.OpenStream(streamParameters, func(in []int32) {
+			for i := range in {
+				binary.Write(&s.audioBuffer, binary.LittleEndian, in[i])
+			}
+			peakRatio, rmsRatio := peakRMSInt32(in)
+			s.recordLevel(peakRatio, rmsRatio)
+			// VAD segmentation is only implemented for the common
+			// mono/16-bit case; 32-bit captures are transcribed as a
+			// whole at StopRecording instead.
+		})
+	} else {
+		stream, err = portaudio.OpenStream(streamParameters, func(in []int16) {
+			for i := range in {
+				binary.Write(&s.audioBuffer, binary.LittleEndian, in[i])
+			}
+			peakRatio, rmsRatio := peakRMSInt16(in)
+			s.recordLevel(peakRatio, rmsRatio)
+			if channels == 1 {
+				s.processVADFrame(in)
+			}
+		})
 	}
 
-	stream, err := portaudio.OpenStream(streamParameters, func(in []int16) {
-		// Convert []int16 to []byte and write to buffer
-		for i := range in {
-			binary.Write(&s.audioBuffer, binary.LittleEndian, in[i])
+	if err != nil && requestedRate != targetDevice.DefaultSampleRate {
+		audioLog.Debugf("Sample rate %.0f not supported on %s, falling back to device default %.0f\n", requestedRate, targetDevice.Name, targetDevice.DefaultSampleRate)
+		requestedRate = targetDevice.DefaultSampleRate
+		streamParameters.SampleRate = requestedRate
+
+		if bitDepth == 32 {
+			stream, err = portaudio.OpenStream(streamParameters, func(in []int32) {
+				for i := range in {
+					binary.Write(&s.audioBuffer, binary.LittleEndian, in[i])
+				}
+				peakRatio, rmsRatio := peakRMSInt32(in)
+				s.recordLevel(peakRatio, rmsRatio)
+			})
+		} else {
+			stream, err = portaudio.OpenStream(streamParameters, func(in []int16) {
+				for i := range in {
+					binary.Write(&s.audioBuffer, binary.LittleEndian, in[i])
+				}
+				peakRatio, rmsRatio := peakRMSInt16(in)
+				s.recordLevel(peakRatio, rmsRatio)
+				if channels == 1 {
+					s.processVADFrame(in)
+				}
+			})
 		}
-	})
+	}
+
 	if err != nil {
 		return fmt.Errorf("failed to open stream on device %s: %w", targetDevice.Name, err)
 	}
@@ -148,14 +271,120 @@ func (s *AudioService) StartRecording(deviceName string) error {
 	}
 
 	s.stream = stream
+	s.activeSampleRate = requestedRate
+	s.activeChannels = channels
+	s.activeBitDepth = bitDepth
 	s.isRecording = true
+	s.currentDeviceName = targetDevice.Name
 	s.recordingStartTime = time.Now()
+	s.inSpeech = false
+	s.segmentBuffer.Reset()
+	s.segmentsText = nil
+
+	s.lastLevelEmit = time.Time{}
+	s.recordingHadSound = false
+	s.levelChan = make(chan levelSample, 8)
+	s.levelDone = make(chan struct{})
+	go s.runLevelMeter(s.levelDone)
+
 	runtime.EventsEmit(s.ctx, "statusUpdate", "Recording...")
-	fmt.Println("Recording started.")
+	audioLog.Debugln("Recording started.")
 
 	return nil
 }
 
+// processVADFrame runs a rolling energy-based voice-activity check over a
+// single callback's worth of samples, accumulating speech into
+// segmentBuffer and flushing a segment for transcription once enough
+// trailing silence has elapsed. It runs on the PortAudio callback
+// goroutine, so it must stay cheap and never block on I/O.
+func (s *AudioService) processVADFrame(in []int16) {
+	var sumSquares float64
+	for _, sample := range in {
+		v := float64(sample)
+		sumSquares += v * v
+	}
+	rms := math.Sqrt(sumSquares / float64(len(in)))
+
+	now := time.Now()
+	speaking := rms >= vadEnergyThreshold
+
+	if speaking {
+		if !s.inSpeech {
+			s.inSpeech = true
+			s.segmentStart = now
+		}
+		for _, sample := range in {
+			binary.Write(&s.segmentBuffer, binary.LittleEndian, sample)
+		}
+		s.silentSince = time.Time{}
+		return
+	}
+
+	if !s.inSpeech {
+		return
+	}
+
+	// Still within the segment - keep recording through brief pauses so
+	// we don't clip words, but track how long we've been silent.
+	for _, sample := range in {
+		binary.Write(&s.segmentBuffer, binary.LittleEndian, sample)
+	}
+
+	if s.silentSince.IsZero() {
+		s.silentSince = now
+		return
+	}
+
+	if now.Sub(s.silentSince) < vadSilenceHangoverMs*time.Millisecond {
+		return
+	}
+
+	// Hangover elapsed: the segment is done.
+	s.inSpeech = false
+	s.silentSince = time.Time{}
+
+	if now.Sub(s.segmentStart) < vadMinSegmentDuration {
+		s.segmentBuffer.Reset()
+		return
+	}
+
+	segmentData := make([]byte, s.segmentBuffer.Len())
+	copy(segmentData, s.segmentBuffer.Bytes())
+	s.segmentBuffer.Reset()
+
+	s.segmentWG.Add(1)
+	go s.transcribeSegment(segmentData)
+}
+
+// transcribeSegment encodes a completed speech segment to WAV, sends it to
+// the TranscriptionService, and emits it over the Wails runtime as a
+// segmentTranscript event so the frontend can show progress during long
+// dictations instead of waiting for StopRecording.
+func (s *AudioService) transcribeSegment(segmentData []byte) {
+	defer s.segmentWG.Done()
+
+	wavBuffer, err := s.encodeWAV(segmentData)
+	if err != nil {
+		audioLog.Debugf("Error encoding segment WAV: %v\n", err)
+		return
+	}
+
+	runtime.EventsEmit(s.ctx, "partialTranscript", "Transcribing segment...")
+
+	transcript, err := s.transcriptionService.TranscribeAudio(s.ctx, wavBuffer)
+	if err != nil {
+		audioLog.Debugf("Error transcribing segment: %v\n", err)
+		return
+	}
+
+	s.segmentsMu.Lock()
+	s.segmentsText = append(s.segmentsText, transcript)
+	s.segmentsMu.Unlock()
+
+	runtime.EventsEmit(s.ctx, "segmentTranscript", transcript)
+}
+
 // StopRecording stops the audio stream and processes the recorded audio.
 func (s *AudioService) StopRecording() error {
 	if !s.isRecording {
@@ -168,59 +397,152 @@ func (s *AudioService) StopRecording() error {
 		return fmt.Errorf("failed to close stream: %w", err)
 	}
 	s.isRecording = false
+	close(s.levelDone)
+
+	// Flush any in-progress segment so trailing speech isn't dropped.
+	if s.inSpeech && s.segmentBuffer.Len() > 0 {
+		segmentData := make([]byte, s.segmentBuffer.Len())
+		copy(segmentData, s.segmentBuffer.Bytes())
+		s.segmentBuffer.Reset()
+		s.inSpeech = false
+
+		s.segmentWG.Add(1)
+		go s.transcribeSegment(segmentData)
+	}
 
 	// Process the recorded audio if we have any
 	if s.audioBuffer.Len() > 0 {
 		runtime.EventsEmit(s.ctx, "statusUpdate", "Transcribing...")
-		fmt.Println("Processing recorded audio...")
+		audioLog.Debugln("Processing recorded audio...")
 
 		// Calculate recording duration
 		recordingDuration := time.Since(s.recordingStartTime).Seconds()
-		fmt.Printf("Recording duration: %.2f seconds\n", recordingDuration)
+		audioLog.Debugf("Recording duration: %.2f seconds\n", recordingDuration)
 
-		wavBuffer, err := s.encodeWAV(s.audioBuffer.Bytes())
-		if err != nil {
-			fmt.Printf("Error encoding WAV: %v\n", err)
-			runtime.EventsEmit(s.ctx, "statusUpdate", "Error")
-			return fmt.Errorf("failed to encode audio: %w", err)
-		}
+		audioBytes := s.audioBuffer.Bytes()
+		s.audioBuffer.Reset()
+
+		startedAt := s.recordingStartTime
+		deviceName := s.currentDeviceName
+		captureConfig := s.captureConfig
 
 		go func() {
-			transcript, err := s.transcriptionService.TranscribeAudio(wavBuffer)
+			event := AuditEvent{
+				StartedAt:          startedAt,
+				DeviceName:         deviceName,
+				CaptureConfig:      captureConfig,
+				DurationSeconds:    recordingDuration,
+				AudioBytes:         int64(len(audioBytes)),
+				TranscriptionModel: transcriptionModel,
+			}
+			defer func() {
+				event.StoppedAt = time.Now()
+				if err := s.auditService.RecordSession(event); err != nil {
+					audioLog.Debugf("Error recording audit session: %v\n", err)
+				}
+			}()
+
+			wavBuffer, err := s.encodeWAV(audioBytes)
 			if err != nil {
-				fmt.Printf("Error during transcription: %v\n", err)
-				runtime.EventsEmit(s.ctx, "statusUpdate", fmt.Sprintf("Error: %v", err))
+				audioLog.Debugf("Error encoding WAV: %v\n", err)
+				runtime.EventsEmit(s.ctx, "statusUpdate", "Error")
+				event.Error = err.Error()
 				return
 			}
+			event.WavSHA256 = hashBytes(wavBuffer.Bytes())
+
+			// Wait for every segment transcription kicked off during
+			// recording (plus the trailing flush above) before emitting
+			// the consolidated final transcript.
+			s.segmentWG.Wait()
+
+			s.segmentsMu.Lock()
+			transcript := strings.Join(s.segmentsText, " ")
+			s.segmentsMu.Unlock()
+
+			// Fall back to transcribing the whole recording in one shot
+			// if VAD never detected a segment (e.g. threshold too high
+			// for this input device).
+			if strings.TrimSpace(transcript) == "" {
+				transcript, err = s.transcriptionService.TranscribeAudio(s.ctx, wavBuffer)
+				if err != nil {
+					audioLog.Debugf("Error during transcription: %v\n", err)
+					runtime.EventsEmit(s.ctx, "statusUpdate", fmt.Sprintf("Error: %v", err))
+					event.Error = err.Error()
+					return
+				}
+			}
 
-			fmt.Printf("Transcript: %s\n", transcript)
+			event.TranscriptSHA256 = hashBytes([]byte(transcript))
+
+			audioLog.Debugf("Transcript: %s\n", transcript)
 			runtime.EventsEmit(s.ctx, "newTranscript", transcript)
 
 			// Record the transcription cost
 			filename := s.fileService.WriteTranscript(transcript)
 			if filename != "" {
 				cost := s.costTrackingService.RecordTranscription(recordingDuration, filename)
+				event.Cost = cost
 
 				// Emit cost update event
 				costSummary := s.costTrackingService.GetCostSummary()
 				runtime.EventsEmit(s.ctx, "costUpdate", costSummary)
 
-				fmt.Printf("Cost for this transcription: $%.4f\n", cost)
+				audioLog.Debugf("Cost for this transcription: $%.4f\n", cost)
 			}
 
 			runtime.EventsEmit(s.ctx, "statusUpdate", "Idle")
 		}()
-
-		s.audioBuffer.Reset()
 	} else {
 		runtime.EventsEmit(s.ctx, "statusUpdate", "Idle")
 	}
 
-	fmt.Println("Recording stopped.")
+	audioLog.Debugln("Recording stopped.")
 	return nil
 }
 
+// encodeWAV encodes raw PCM bytes captured at s.activeSampleRate /
+// s.activeChannels / s.activeBitDepth into a WAV file. If the capture
+// config requests it, the audio is downmixed to mono and resampled to
+// 16kHz first - Whisper prefers 16kHz mono and this shrinks upload size
+// for stereo/high-rate captures considerably.
 func (s *AudioService) encodeWAV(input []byte) (*bytes.Buffer, error) {
+	numChannels := s.activeChannels
+	if numChannels == 0 {
+		numChannels = 1
+	}
+	bitDepth := s.activeBitDepth
+	if bitDepth == 0 {
+		bitDepth = 16
+	}
+	sourceRate := s.activeSampleRate
+	if sourceRate == 0 {
+		sourceRate = sampleRate
+	}
+
+	bytesPerSample := bitDepth / 8
+	numFrames := len(input) / (bytesPerSample * numChannels)
+	samples := make([]int, numFrames*numChannels)
+
+	for i := 0; i < numFrames*numChannels; i++ {
+		off := i * bytesPerSample
+		if bitDepth == 32 {
+			samples[i] = int(int32(binary.LittleEndian.Uint32(input[off : off+4])))
+		} else {
+			samples[i] = int(int16(binary.LittleEndian.Uint16(input[off : off+2])))
+		}
+	}
+
+	outRate := sourceRate
+	outChannels := numChannels
+
+	if s.captureConfig.ResampleTo16kMono && (numChannels != 1 || sourceRate != 16000) {
+		samples = downmixToMono(samples, numChannels)
+		samples = resampleLinear(samples, sourceRate, 16000)
+		outRate = 16000
+		outChannels = 1
+	}
+
 	// The WAV encoder needs an io.WriteSeeker. A temp file is a simple way to provide one.
 	tmpfile, err := os.CreateTemp("", "recording-*.wav")
 	if err != nil {
@@ -228,27 +550,15 @@ func (s *AudioService) encodeWAV(input []byte) (*bytes.Buffer, error) {
 	}
 	defer os.Remove(tmpfile.Name()) // Clean up the temp file
 
-	numSamples := len(input) / 2 // 2 bytes per int16
-	audioFormat := 1             // PCM
-	numChannels := 1
-	bitDepth := 16
-
-	e := wav.NewEncoder(tmpfile, sampleRate, bitDepth, numChannels, audioFormat)
-
-	// PortAudio gives us signed 16-bit integers. We need to convert the
-	// raw bytes back to that format.
-	intBuf := make([]int, numSamples)
-	for i := 0; i < numSamples; i++ {
-		sample := int16(binary.LittleEndian.Uint16(input[i*2 : (i+1)*2]))
-		intBuf[i] = int(sample)
-	}
+	audioFormat := 1 // PCM
+	e := wav.NewEncoder(tmpfile, int(outRate), bitDepth, outChannels, audioFormat)
 
 	audioBuf := &audio.IntBuffer{
 		Format: &audio.Format{
-			NumChannels: numChannels,
-			SampleRate:  sampleRate,
+			NumChannels: outChannels,
+			SampleRate:  int(outRate),
 		},
-		Data:           intBuf,
+		Data:           samples,
 		SourceBitDepth: bitDepth,
 	}
 
@@ -268,6 +578,55 @@ func (s *AudioService) encodeWAV(input []byte) (*bytes.Buffer, error) {
 	return bytes.NewBuffer(wavData), nil
 }
 
+// downmixToMono averages interleaved channels down to a single channel.
+func downmixToMono(samples []int, channels int) []int {
+	if channels <= 1 {
+		return samples
+	}
+
+	numFrames := len(samples) / channels
+	mono := make([]int, numFrames)
+	for i := 0; i < numFrames; i++ {
+		sum := 0
+		for c := 0; c < channels; c++ {
+			sum += samples[i*channels+c]
+		}
+		mono[i] = sum / channels
+	}
+	return mono
+}
+
+// resampleLinear resamples a mono sample sequence from fromRate to toRate
+// using simple linear interpolation. It's not a proper sinc-windowed
+// resampler, but it's more than adequate for shrinking upload size ahead
+// of a Whisper transcription.
+func resampleLinear(samples []int, fromRate, toRate float64) []int {
+	if fromRate == toRate || len(samples) == 0 {
+		return samples
+	}
+
+	ratio := fromRate / toRate
+	outLen := int(float64(len(samples)) / ratio)
+	out := make([]int, outLen)
+
+	for i := 0; i < outLen; i++ {
+		srcPos := float64(i) * ratio
+		srcIdx := int(srcPos)
+		frac := srcPos - float64(srcIdx)
+
+		if srcIdx+1 >= len(samples) {
+			out[i] = samples[len(samples)-1]
+			continue
+		}
+
+		a := float64(samples[srcIdx])
+		b := float64(samples[srcIdx+1])
+		out[i] = int(a + (b-a)*frac)
+	}
+
+	return out
+}
+
 // SetContext sets the application context for the service.
 func (s *AudioService) SetContext(ctx context.Context) {
 	s.ctx = ctx
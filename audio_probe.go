@@ -0,0 +1,505 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var audioProbeLog = GetFacility("audioprobe")
+
+// AudioProbeResult is the metadata recovered from an audio file's actual
+// stream headers, used for accurate cost estimates and job planning instead
+// of estimateDurationSecondsByFileSize's file-size-and-guessed-bitrate
+// approximation.
+type AudioProbeResult struct {
+	DurationSeconds float64 `json:"durationSeconds"`
+	SampleRate      int     `json:"sampleRate"`
+	Channels        int     `json:"channels"`
+	Codec           string  `json:"codec"`
+	BitRate         int     `json:"bitRate"`
+}
+
+// AudioProbe inspects an audio file and returns its real duration and
+// stream parameters.
+type AudioProbe interface {
+	Probe(path string) (AudioProbeResult, error)
+}
+
+// audioProbes is the probe chain probeAudioFile tries in order: ffprobe
+// handles every container correctly (including VBR) but requires ffmpeg on
+// PATH, so the pure-Go header parser is tried second for environments
+// without it.
+var audioProbes = []AudioProbe{ffprobeAudioProbe{}, headerAudioProbe{}}
+
+// probeAudioFile runs path through audioProbes in order, returning the
+// first successful result.
+func probeAudioFile(path string) (AudioProbeResult, error) {
+	var lastErr error
+	for _, probe := range audioProbes {
+		result, err := probe.Probe(path)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		audioProbeLog.Debugf("probe failed for %s: %v\n", path, err)
+	}
+	return AudioProbeResult{}, lastErr
+}
+
+// ffprobeAudioProbe shells out to ffprobe.
+type ffprobeAudioProbe struct{}
+
+type ffprobeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		SampleRate string `json:"sample_rate"`
+		Channels   int    `json:"channels"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"streams"`
+}
+
+func (ffprobeAudioProbe) Probe(path string) (AudioProbeResult, error) {
+	out, err := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration,bit_rate", "-show_streams", "-of", "json", path).Output()
+	if err != nil {
+		return AudioProbeResult{}, fmt.Errorf("ffprobe: %w", err)
+	}
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return AudioProbeResult{}, fmt.Errorf("ffprobe output: %w", err)
+	}
+
+	result := AudioProbeResult{}
+	if d, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil {
+		result.DurationSeconds = d
+	}
+	if b, err := strconv.Atoi(parsed.Format.BitRate); err == nil {
+		result.BitRate = b
+	}
+
+	for _, stream := range parsed.Streams {
+		if stream.CodecType != "audio" {
+			continue
+		}
+		result.Codec = stream.CodecName
+		result.Channels = stream.Channels
+		if sr, err := strconv.Atoi(stream.SampleRate); err == nil {
+			result.SampleRate = sr
+		}
+		if result.BitRate == 0 {
+			if b, err := strconv.Atoi(stream.BitRate); err == nil {
+				result.BitRate = b
+			}
+		}
+		break
+	}
+
+	if result.DurationSeconds == 0 {
+		return AudioProbeResult{}, fmt.Errorf("ffprobe: no duration found for %s", path)
+	}
+
+	return result, nil
+}
+
+// headerAudioProbe parses WAV, FLAC, MP3, M4A, and OGG headers directly,
+// used when ffprobe isn't installed. WAV and FLAC durations are exact;
+// MP3 duration is approximated from the first frame's bitrate, which is
+// exact for CBR and a reasonable estimate for VBR - the same tradeoff
+// estimateDurationSecondsByFileSize makes, but anchored to a bitrate read
+// from the file instead of a file-size guess.
+type headerAudioProbe struct{}
+
+func (headerAudioProbe) Probe(path string) (AudioProbeResult, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return probeWAV(path)
+	case ".flac":
+		return probeFLAC(path)
+	case ".mp3":
+		return probeMP3(path)
+	case ".m4a":
+		return probeM4A(path)
+	case ".ogg":
+		return probeOGG(path)
+	default:
+		return AudioProbeResult{}, fmt.Errorf("no pure-Go header parser for %s", path)
+	}
+}
+
+// probeWAV reads the fmt and data chunks of a RIFF/WAVE file to compute
+// an exact duration from the PCM data size and byte rate.
+func probeWAV(path string) (AudioProbeResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return AudioProbeResult{}, fmt.Errorf("not a WAV file: %s", path)
+	}
+
+	var channels, sampleRate, byteRate, dataSize int
+	offset := 12
+	for offset+8 <= len(data) {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if body+chunkSize > len(data) {
+			break
+		}
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return AudioProbeResult{}, fmt.Errorf("truncated fmt chunk: %s", path)
+			}
+			channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			byteRate = int(binary.LittleEndian.Uint32(data[body+8 : body+12]))
+		case "data":
+			dataSize = chunkSize
+		}
+
+		offset = body + chunkSize + chunkSize%2 // chunks are word-aligned
+	}
+
+	if byteRate == 0 || dataSize == 0 {
+		return AudioProbeResult{}, fmt.Errorf("missing fmt/data chunk: %s", path)
+	}
+
+	return AudioProbeResult{
+		DurationSeconds: float64(dataSize) / float64(byteRate),
+		SampleRate:      sampleRate,
+		Channels:        channels,
+		Codec:           "pcm",
+		BitRate:         byteRate * 8,
+	}, nil
+}
+
+// probeFLAC parses the mandatory STREAMINFO metadata block (always the
+// first block right after the "fLaC" magic) for sample rate, channels,
+// and total sample count, giving an exact duration.
+func probeFLAC(path string) (AudioProbeResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(file, magic); err != nil || string(magic) != "fLaC" {
+		return AudioProbeResult{}, fmt.Errorf("not a FLAC file: %s", path)
+	}
+
+	blockHeader := make([]byte, 4)
+	if _, err := io.ReadFull(file, blockHeader); err != nil {
+		return AudioProbeResult{}, err
+	}
+	blockLen := int(blockHeader[1])<<16 | int(blockHeader[2])<<8 | int(blockHeader[3])
+
+	streamInfo := make([]byte, blockLen)
+	if _, err := io.ReadFull(file, streamInfo); err != nil {
+		return AudioProbeResult{}, err
+	}
+	if len(streamInfo) < 18 {
+		return AudioProbeResult{}, fmt.Errorf("truncated STREAMINFO: %s", path)
+	}
+
+	// Sample rate (20 bits), channels-1 (3 bits), and total samples (36
+	// bits) are packed starting at byte 10 of STREAMINFO per the FLAC spec.
+	sampleRate := int(streamInfo[10])<<12 | int(streamInfo[11])<<4 | int(streamInfo[12])>>4
+	channels := int((streamInfo[12]>>1)&0x07) + 1
+	totalSamples := int(streamInfo[13]&0x0F)<<32 | int(streamInfo[14])<<24 | int(streamInfo[15])<<16 | int(streamInfo[16])<<8 | int(streamInfo[17])
+
+	if sampleRate == 0 || totalSamples == 0 {
+		return AudioProbeResult{}, fmt.Errorf("could not parse STREAMINFO: %s", path)
+	}
+
+	return AudioProbeResult{
+		DurationSeconds: float64(totalSamples) / float64(sampleRate),
+		SampleRate:      sampleRate,
+		Channels:        channels,
+		Codec:           "flac",
+	}, nil
+}
+
+var mp3BitratesKbps = []int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+var mp3SampleRates = []int{44100, 48000, 32000}
+
+// probeMP3 reads the first MPEG-1 Layer III frame header (skipping a
+// leading ID3v2 tag if present) for sample rate, channel mode, and
+// bitrate, then estimates duration from the remaining file size and that
+// bitrate.
+func probeMP3(path string) (AudioProbeResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return AudioProbeResult{}, fmt.Errorf("too small to be MP3: %s", path)
+	}
+
+	offset := int64(0)
+	if string(header[0:3]) == "ID3" {
+		tagSize := int64(header[6])<<21 | int64(header[7])<<14 | int64(header[8])<<7 | int64(header[9])
+		offset = 10 + tagSize
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return AudioProbeResult{}, err
+	}
+
+	frame := make([]byte, 4)
+	if _, err := io.ReadFull(file, frame); err != nil {
+		return AudioProbeResult{}, fmt.Errorf("no MPEG frame found: %s", path)
+	}
+	if frame[0] != 0xFF || frame[1]&0xE0 != 0xE0 {
+		return AudioProbeResult{}, fmt.Errorf("no MPEG sync word found: %s", path)
+	}
+
+	bitrateIdx := int(frame[2] >> 4)
+	sampleRateIdx := int((frame[2] >> 2) & 0x03)
+	channelMode := frame[3] >> 6
+	if bitrateIdx >= len(mp3BitratesKbps) || sampleRateIdx >= len(mp3SampleRates) {
+		return AudioProbeResult{}, fmt.Errorf("unsupported MPEG frame header: %s", path)
+	}
+
+	bitRate := mp3BitratesKbps[bitrateIdx] * 1000
+	if bitRate == 0 {
+		return AudioProbeResult{}, fmt.Errorf("free-format MP3 not supported: %s", path)
+	}
+	channels := 2
+	if channelMode == 3 {
+		channels = 1
+	}
+
+	audioBytes := info.Size() - offset
+	return AudioProbeResult{
+		DurationSeconds: float64(audioBytes*8) / float64(bitRate),
+		SampleRate:      mp3SampleRates[sampleRateIdx],
+		Channels:        channels,
+		Codec:           "mp3",
+		BitRate:         bitRate,
+	}, nil
+}
+
+// probeM4A walks the ISO-BMFF box tree for moov -> mvhd, which carries
+// the movie's timescale and total duration regardless of the audio
+// codec inside (AAC, ALAC, etc).
+func probeM4A(path string) (AudioProbeResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+
+	mvhd := findM4ABox(findTopLevelM4ABox(data, "moov"), "mvhd")
+	if mvhd == nil {
+		return AudioProbeResult{}, fmt.Errorf("no mvhd atom found: %s", path)
+	}
+	if len(mvhd) < 12 {
+		return AudioProbeResult{}, fmt.Errorf("truncated mvhd atom: %s", path)
+	}
+
+	var timescale, duration uint64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return AudioProbeResult{}, fmt.Errorf("truncated mvhd atom: %s", path)
+		}
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+
+	if timescale == 0 {
+		return AudioProbeResult{}, fmt.Errorf("zero timescale in mvhd: %s", path)
+	}
+
+	return AudioProbeResult{
+		DurationSeconds: float64(duration) / float64(timescale),
+		Codec:           "aac",
+	}, nil
+}
+
+// findTopLevelM4ABox returns the payload (excluding its 8-byte header) of
+// the first top-level box named name, or nil if not found.
+func findTopLevelM4ABox(data []byte, name string) []byte {
+	offset := 0
+	for offset+8 <= len(data) {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		boxName := string(data[offset+4 : offset+8])
+		if size < 8 || offset+size > len(data) {
+			return nil
+		}
+		if boxName == name {
+			return data[offset+8 : offset+size]
+		}
+		offset += size
+	}
+	return nil
+}
+
+// findM4ABox returns the payload of the first box named name directly
+// inside parent, or nil if parent is nil or has no such child.
+func findM4ABox(parent []byte, name string) []byte {
+	if parent == nil {
+		return nil
+	}
+
+	offset := 0
+	for offset+8 <= len(parent) {
+		size := int(binary.BigEndian.Uint32(parent[offset : offset+4]))
+		boxName := string(parent[offset+4 : offset+8])
+		if size < 8 || offset+size > len(parent) {
+			return nil
+		}
+		if boxName == name {
+			return parent[offset+8 : offset+size]
+		}
+		offset += size
+	}
+	return nil
+}
+
+// probeOGG reads the first page's Vorbis identification header for
+// sample rate and channels, then reads the granule position (total
+// sample count) off the last page in the file to compute duration.
+func probeOGG(path string) (AudioProbeResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+	if len(data) < 27 || string(data[0:4]) != "OggS" {
+		return AudioProbeResult{}, fmt.Errorf("not an Ogg file: %s", path)
+	}
+
+	numSegments := int(data[26])
+	packetStart := 27 + numSegments
+	if packetStart+16 > len(data) || data[packetStart] != 0x01 || string(data[packetStart+1:packetStart+7]) != "vorbis" {
+		return AudioProbeResult{}, fmt.Errorf("not a Vorbis stream: %s", path)
+	}
+
+	channels := int(data[packetStart+11])
+	sampleRate := int(binary.LittleEndian.Uint32(data[packetStart+12 : packetStart+16]))
+
+	lastPageStart := bytes.LastIndex(data, []byte("OggS"))
+	if lastPageStart < 0 || lastPageStart+14 > len(data) {
+		return AudioProbeResult{}, fmt.Errorf("could not find final Ogg page: %s", path)
+	}
+	granule := binary.LittleEndian.Uint64(data[lastPageStart+6 : lastPageStart+14])
+
+	if sampleRate == 0 || granule == 0 {
+		return AudioProbeResult{}, fmt.Errorf("could not parse Ogg headers: %s", path)
+	}
+
+	return AudioProbeResult{
+		DurationSeconds: float64(granule) / float64(sampleRate),
+		SampleRate:      sampleRate,
+		Channels:        channels,
+		Codec:           "vorbis",
+	}, nil
+}
+
+// probeAudioFileCached returns path's probe result, reusing a prior result
+// cached in Convex by content hash (via loadFileHashState's already-persisted
+// block_hash.go state, so this doesn't trigger a rehash) instead of shelling
+// out to ffprobe again for a file EstimateCost has already seen. convexClient
+// may be nil (no caching available), in which case this always re-probes.
+func probeAudioFileCached(convexClient *ConvexClient, path string) (AudioProbeResult, error) {
+	var hash string
+	if convexClient != nil {
+		if state, ok := loadFileHashState(convexClient, path); ok {
+			hash = state.Hash
+		}
+	}
+
+	if hash != "" {
+		if cached, ok := loadAudioMetadata(convexClient, hash); ok {
+			return cached, nil
+		}
+	}
+
+	result, err := probeAudioFile(path)
+	if err != nil {
+		return AudioProbeResult{}, err
+	}
+
+	if hash != "" {
+		if err := upsertAudioMetadata(convexClient, hash, result); err != nil {
+			audioProbeLog.Debugf("failed to cache audio metadata for %s: %v\n", path, err)
+		}
+	}
+
+	return result, nil
+}
+
+// loadAudioMetadata fetches a previously cached probe result for hash, if
+// any. It reports ok=false on a missing or unparseable record so the caller
+// just re-probes.
+func loadAudioMetadata(convexClient *ConvexClient, hash string) (AudioProbeResult, bool) {
+	result, err := convexClient.CallQuery("files:getMetadataByHash", map[string]interface{}{"hash": hash})
+	if err != nil || result == nil {
+		return AudioProbeResult{}, false
+	}
+
+	meta, ok := result.(map[string]interface{})
+	if !ok {
+		return AudioProbeResult{}, false
+	}
+
+	duration, ok := meta["durationSeconds"].(float64)
+	if !ok || duration == 0 {
+		return AudioProbeResult{}, false
+	}
+
+	probe := AudioProbeResult{DurationSeconds: duration}
+	if sr, ok := meta["sampleRate"].(float64); ok {
+		probe.SampleRate = int(sr)
+	}
+	if ch, ok := meta["channels"].(float64); ok {
+		probe.Channels = int(ch)
+	}
+	if codec, ok := meta["codec"].(string); ok {
+		probe.Codec = codec
+	}
+	if br, ok := meta["bitRate"].(float64); ok {
+		probe.BitRate = int(br)
+	}
+
+	return probe, true
+}
+
+// upsertAudioMetadata persists probe onto the Convex metadata record for
+// hash via files:upsertAudioMetadata, so a later scan of an unchanged file
+// reuses the cached probe result instead of re-running ffprobe.
+func upsertAudioMetadata(convexClient *ConvexClient, hash string, probe AudioProbeResult) error {
+	_, err := convexClient.CallMutation("files:upsertAudioMetadata", map[string]interface{}{
+		"hash":            hash,
+		"durationSeconds": probe.DurationSeconds,
+		"sampleRate":      probe.SampleRate,
+		"channels":        probe.Channels,
+		"codec":           probe.Codec,
+		"bitRate":         probe.BitRate,
+	})
+	return err
+}
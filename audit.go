@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var auditLog = GetFacility("audit")
+
+const (
+	auditLogDir         = "audit_logs"
+	auditDefaultMaxSize = 10 * 1024 * 1024 // rotate a day's segment at 10MB even if the day hasn't rolled over
+)
+
+// AuditEvent is a single append-only record of a recording/transcription
+// session, written as one line of JSON to the current day's audit segment.
+type AuditEvent struct {
+	StartedAt          time.Time     `json:"startedAt"`
+	StoppedAt          time.Time     `json:"stoppedAt"`
+	DeviceName         string        `json:"deviceName"`
+	CaptureConfig      CaptureConfig `json:"captureConfig"`
+	DurationSeconds    float64       `json:"durationSeconds"`
+	AudioBytes         int64         `json:"audioBytes"`
+	WavSHA256          string        `json:"wavSha256"`
+	TranscriptionModel string        `json:"transcriptionModel"`
+	TranscriptSHA256   string        `json:"transcriptSha256"`
+	TokenCount         int           `json:"tokenCount"`
+	Cost               float64       `json:"cost"`
+	Error              string        `json:"error,omitempty"`
+}
+
+// AuditFilter narrows SearchAuditLog results beyond the time range.
+type AuditFilter struct {
+	DeviceName string `json:"deviceName,omitempty"`
+	HasError   bool   `json:"hasError,omitempty"`
+}
+
+// AuditService writes a durable, searchable history of recording sessions,
+// separate from the transcript text files themselves, so costs and
+// transcriptions can be reconciled after the fact. Segments rotate daily
+// (or at auditDefaultMaxSize) and closed segments are gzipped; the current
+// day's segment is kept uncompressed so it can be tailed.
+type AuditService struct {
+	mutex sync.Mutex
+
+	dir      string
+	maxBytes int64
+
+	currentDate string
+	currentFile *os.File
+	currentSize int64
+	partsByDate map[string]int
+}
+
+// NewAuditService creates a new AuditService, ensuring its log directory exists.
+func NewAuditService() *AuditService {
+	if err := os.MkdirAll(auditLogDir, 0755); err != nil {
+		auditLog.Debugf("Error creating audit log directory: %v\n", err)
+	}
+
+	return &AuditService{
+		dir:         auditLogDir,
+		maxBytes:    auditDefaultMaxSize,
+		partsByDate: make(map[string]int),
+	}
+}
+
+// RecordSession appends an audit event to today's segment, rotating first
+// if the day has changed or the current segment has grown past maxBytes.
+func (a *AuditService) RecordSession(event AuditEvent) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if err := a.ensureCurrentFile(); err != nil {
+		return fmt.Errorf("failed to open audit segment: %w", err)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+	data = append(data, '\n')
+
+	n, err := a.currentFile.Write(data)
+	a.currentSize += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+
+	return nil
+}
+
+// ensureCurrentFile opens today's plain-text segment, rotating (gzipping)
+// the previous one if the date rolled over or it exceeded maxBytes. Caller
+// must hold a.mutex.
+func (a *AuditService) ensureCurrentFile() error {
+	today := time.Now().Format("2006-01-02")
+
+	if a.currentFile != nil && a.currentDate == today && a.currentSize < a.maxBytes {
+		return nil
+	}
+
+	if a.currentFile != nil {
+		a.rotateCurrent()
+	}
+
+	path := filepath.Join(a.dir, fmt.Sprintf("audit-%s.jsonl", today))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	a.currentDate = today
+	a.currentFile = f
+	a.currentSize = info.Size()
+	return nil
+}
+
+// rotateCurrent closes the open segment and gzips it to
+// audit-<date>-<part>.jsonl.gz, freeing up the plain filename for the next
+// segment on the same day (or the day that just started). Caller must hold
+// a.mutex.
+func (a *AuditService) rotateCurrent() {
+	path := a.currentFile.Name()
+	date := a.currentDate
+
+	a.currentFile.Close()
+	a.currentFile = nil
+	a.currentSize = 0
+
+	part := a.partsByDate[date]
+	a.partsByDate[date] = part + 1
+
+	gzPath := filepath.Join(a.dir, fmt.Sprintf("audit-%s-%d.jsonl.gz", date, part))
+	if err := gzipFile(path, gzPath); err != nil {
+		auditLog.Debugf("Error compressing audit segment %s: %v\n", path, err)
+		return
+	}
+
+	if err := os.Remove(path); err != nil {
+		auditLog.Debugf("Error removing uncompressed audit segment %s: %v\n", path, err)
+	}
+}
+
+// SearchAuditLog returns every audit event between from and to (inclusive)
+// matching filter, transparently reading both the current uncompressed
+// segment and any gzipped segments that fall in the requested range.
+func (a *AuditService) SearchAuditLog(from, to time.Time, filter AuditFilter) ([]AuditEvent, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log directory: %w", err)
+	}
+
+	var results []AuditEvent
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		path := filepath.Join(a.dir, name)
+
+		var reader io.Reader
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		if filepath.Ext(name) == ".gz" {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				file.Close()
+				continue
+			}
+			defer gz.Close()
+			reader = gz
+		} else {
+			reader = file
+		}
+
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var event AuditEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
+			}
+
+			if event.StoppedAt.Before(from) || event.StoppedAt.After(to) {
+				continue
+			}
+			if filter.DeviceName != "" && event.DeviceName != filter.DeviceName {
+				continue
+			}
+			if filter.HasError && event.Error == "" {
+				continue
+			}
+
+			results = append(results, event)
+		}
+
+		file.Close()
+	}
+
+	return results, nil
+}
+
+// hashBytes returns the hex-encoded SHA-256 digest of data, used to
+// fingerprint audio and transcript content in audit events.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// gzipFile compresses srcPath into a new file at dstPath.
+func gzipFile(srcPath, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+var blockHashLog = GetFacility("blockhash")
+
+// defaultBlockSize is the unit of work for content-defined hashing. 128 KiB
+// keeps per-block overhead low while still letting a rescan skip most of a
+// multi-GB audio file when only a small region changed.
+const defaultBlockSize = 128 * 1024
+
+// fastHashEnvVar selects xxhash (non-cryptographic, much faster) over
+// SHA-256 for per-block hashing. The combined file hash is always SHA-256
+// of the block hash list, so callers comparing file hashes across a mix of
+// fast/slow runs would still need to rehash - this is meant to be set
+// consistently per deployment, not toggled per scan.
+const fastHashEnvVar = "VOICEWORKER_FAST_HASH"
+
+// FileBlockHash is the hash of a single fixed-size block of a file,
+// identified by its byte offset from the start of the file.
+type FileBlockHash struct {
+	Offset int64  `json:"offset"`
+	Hash   string `json:"hash"`
+}
+
+// FileHashState is the persisted hashing state for a file: the block
+// layout and per-block hashes used to detect unchanged files without a
+// full rehash, plus the combined file hash derived from them.
+type FileHashState struct {
+	Size        int64           `json:"size"`
+	ModTime     time.Time       `json:"modTime"`
+	BlockSize   int64           `json:"blockSize"`
+	BlockHashes []FileBlockHash `json:"blockHashes"`
+	Hash        string          `json:"hash"`
+}
+
+// useFastBlockHash reports whether block hashing should use xxhash instead
+// of SHA-256, per VOICEWORKER_FAST_HASH.
+func useFastBlockHash() bool {
+	return os.Getenv(fastHashEnvVar) != ""
+}
+
+// hashFileBlocks splits path into fixed-size blocks, hashes each one, and
+// derives the overall file hash from the concatenated block hashes. It
+// checks ctx between blocks so a long hash of a multi-GB file can be
+// cancelled (e.g. the app shutting down mid-scan).
+func hashFileBlocks(ctx context.Context, path string, blockSize int64) (FileHashState, error) {
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileHashState{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return FileHashState{}, err
+	}
+	defer file.Close()
+
+	fast := useFastBlockHash()
+	buf := make([]byte, blockSize)
+	var blocks []FileBlockHash
+	var offset int64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return FileHashState{}, ctx.Err()
+		default:
+		}
+
+		n, readErr := io.ReadFull(file, buf)
+		if n > 0 {
+			blocks = append(blocks, FileBlockHash{
+				Offset: offset,
+				Hash:   hashBlock(buf[:n], fast),
+			})
+			offset += int64(n)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return FileHashState{}, readErr
+		}
+	}
+
+	return FileHashState{
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		BlockSize:   blockSize,
+		BlockHashes: blocks,
+		Hash:        combineBlockHashes(blocks),
+	}, nil
+}
+
+// hashBlock hashes a single block with either xxhash (fast path) or
+// SHA-256 (default, cryptographic).
+func hashBlock(data []byte, fast bool) string {
+	if fast {
+		return fmt.Sprintf("%016x", xxhash.Sum64(data))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// combineBlockHashes derives a single file hash from an ordered list of
+// block hashes, SHA-256 of their concatenation, so the overall file hash
+// changes if any block's content or position changes.
+func combineBlockHashes(blocks []FileBlockHash) string {
+	h := sha256.New()
+	for _, b := range blocks {
+		h.Write([]byte(b.Hash))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// unchanged reports whether state still matches a file of the given size
+// and modtime, meaning the file can be assumed unmodified since state was
+// computed and a rehash can be skipped entirely.
+func (state FileHashState) unchanged(size int64, modTime time.Time) bool {
+	return state.Size == size && state.ModTime.Equal(modTime)
+}
+
+// calculateFileHash returns the content hash for path, reusing the block
+// hashes persisted on the Convex file record when size and modtime haven't
+// changed since the last scan so a multi-GB file that only had metadata
+// touched skips a full rehash. convexClient may be nil for callers not
+// wired up to Convex, in which case every call rehashes from scratch.
+func calculateFileHash(ctx context.Context, convexClient *ConvexClient, path string) (string, error) {
+	start := time.Now()
+	defer func() {
+		hashDurationSeconds.Observe(time.Since(start).Seconds())
+	}()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if convexClient != nil {
+		if prior, ok := loadFileHashState(convexClient, path); ok && prior.unchanged(info.Size(), info.ModTime()) {
+			blockHashLog.Debugf("size/modtime unchanged, reusing block hashes: %s\n", path)
+			return prior.Hash, nil
+		}
+	}
+
+	state, err := hashFileBlocks(ctx, path, defaultBlockSize)
+	if err != nil {
+		return "", err
+	}
+	hashBytesTotal.Add(float64(state.Size))
+
+	if convexClient != nil {
+		if err := upsertFileHashState(convexClient, path, state); err != nil {
+			blockHashLog.Debugf("failed to persist block hashes for %s: %v\n", path, err)
+		}
+	}
+
+	return state.Hash, nil
+}
+
+// loadFileHashState fetches the block-hash state persisted for path on its
+// Convex file record, if any. It reports ok=false on a missing record or
+// any field it can't parse, so a malformed/older record just falls back to
+// a full rehash rather than failing the caller.
+func loadFileHashState(convexClient *ConvexClient, path string) (FileHashState, bool) {
+	result, err := convexClient.CallQuery("files:getByPath", map[string]interface{}{"path": path})
+	if err != nil || result == nil {
+		return FileHashState{}, false
+	}
+
+	file, ok := result.(map[string]interface{})
+	if !ok {
+		return FileHashState{}, false
+	}
+
+	hash, _ := file["hash"].(string)
+	sizeBytes, sizeOk := file["sizeBytes"].(float64)
+	modTimeMs, modTimeOk := file["modTimeMs"].(float64)
+	blockSize, blockSizeOk := file["blockSize"].(float64)
+	if hash == "" || !sizeOk || !modTimeOk || !blockSizeOk {
+		return FileHashState{}, false
+	}
+
+	return FileHashState{
+		Size:      int64(sizeBytes),
+		ModTime:   time.UnixMilli(int64(modTimeMs)),
+		BlockSize: int64(blockSize),
+		Hash:      hash,
+	}, true
+}
+
+// upsertFileHashState persists state's block-hash layout onto the Convex
+// file record for path via files:upsertBlocks, so the next scan or watch
+// event can skip rehashing unchanged files and downstream diffing/dedup
+// can reuse the per-block hashes without recomputing them.
+func upsertFileHashState(convexClient *ConvexClient, path string, state FileHashState) error {
+	blocks := make([]map[string]interface{}, len(state.BlockHashes))
+	for i, b := range state.BlockHashes {
+		blocks[i] = map[string]interface{}{
+			"offset": b.Offset,
+			"hash":   b.Hash,
+		}
+	}
+
+	_, err := convexClient.CallMutation("files:upsertBlocks", map[string]interface{}{
+		"path":        path,
+		"sizeBytes":   state.Size,
+		"modTimeMs":   state.ModTime.UnixMilli(),
+		"blockSize":   state.BlockSize,
+		"blockHashes": blocks,
+		"hash":        state.Hash,
+	})
+	return err
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+const budgetConfigFile = "budget_config.json"
+
+// budgetWarnThreshold is the fraction of a limit at which CheckBudget sets
+// BudgetStatus.Warn, so the UI can show a soft warning before a job is
+// actually blocked.
+const budgetWarnThreshold = 0.8
+
+// BudgetConfig caps how much CostTrackingService will let JobQueueServiceImpl
+// spend before checkJobBudget starts blocking new jobs. A zero limit means
+// unlimited - the default, matching behavior before budgets existed.
+type BudgetConfig struct {
+	DailyLimitUSD   float64 `json:"dailyLimitUsd"`
+	MonthlyLimitUSD float64 `json:"monthlyLimitUsd"`
+	SessionLimitUSD float64 `json:"sessionLimitUsd"`
+
+	// PerParserDailyLimitUSD caps an individual parser's own daily spend
+	// (e.g. transcription) on top of the aggregate DailyLimitUSD, so a
+	// runaway folder scan can't drain an entire day's budget on one parser.
+	PerParserDailyLimitUSD map[string]float64 `json:"perParserDailyLimitUsd,omitempty"`
+}
+
+// defaultBudgetConfig leaves every limit unlimited, matching the app's
+// behavior before budgets existed.
+func defaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{}
+}
+
+// loadBudgetConfig prefers the config persisted in Convex, falling back to
+// the local JSON file if convexClient is nil or the Convex read fails (e.g.
+// offline), so a budget set from another device is picked up when reachable
+// but still works fully offline otherwise.
+func loadBudgetConfig(convexClient *ConvexClient) BudgetConfig {
+	if convexClient != nil {
+		if result, err := convexClient.CallQuery("budget:get", map[string]interface{}{}); err == nil {
+			if cfg, ok := decodeBudgetConfig(result); ok {
+				return cfg
+			}
+		}
+	}
+
+	return loadBudgetConfigLocal()
+}
+
+// loadBudgetConfigLocal reads the persisted budget config file, falling back
+// to defaultBudgetConfig if none has been saved yet or it fails to parse.
+func loadBudgetConfigLocal() BudgetConfig {
+	cfg := defaultBudgetConfig()
+
+	data, err := os.ReadFile(budgetConfigFile)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Printf("Error parsing budget config file: %v\n", err)
+		return defaultBudgetConfig()
+	}
+
+	return cfg
+}
+
+// saveBudgetConfig persists cfg to both Convex (best-effort, so a budget set
+// while offline still takes effect locally) and the local JSON file via
+// writeFileAtomic, so a crash mid-write can't corrupt it the way the
+// original cost_data.json could.
+func saveBudgetConfig(convexClient *ConvexClient, cfg BudgetConfig) error {
+	if convexClient != nil {
+		if _, err := convexClient.CallMutation("budget:set", map[string]interface{}{
+			"dailyLimitUsd":          cfg.DailyLimitUSD,
+			"monthlyLimitUsd":        cfg.MonthlyLimitUSD,
+			"sessionLimitUsd":        cfg.SessionLimitUSD,
+			"perParserDailyLimitUsd": cfg.PerParserDailyLimitUSD,
+		}); err != nil {
+			log.Printf("Warning: failed to persist budget config to Convex: %v", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode budget config: %w", err)
+	}
+
+	return writeFileAtomic(budgetConfigFile, data, 0644)
+}
+
+// decodeBudgetConfig re-marshals a Convex query result (a
+// map[string]interface{} produced by its generic JSON decoding) back through
+// json so it lands in a BudgetConfig, rather than hand-picking fields off
+// the map the way GetQueueStats does - simpler here since every field is a
+// plain number or map of numbers.
+func decodeBudgetConfig(raw interface{}) (BudgetConfig, bool) {
+	if raw == nil {
+		return BudgetConfig{}, false
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return BudgetConfig{}, false
+	}
+
+	var cfg BudgetConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return BudgetConfig{}, false
+	}
+
+	return cfg, true
+}
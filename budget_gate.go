@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// budgetGateSweepInterval is how often budgetGateSweeper rechecks
+// blocked_by_budget jobs against the current budget, so a job blocked by a
+// daily cap gets requeued automatically once the day rolls over (or the
+// limit is raised) instead of sitting there until a human calls RetryJob.
+const budgetGateSweepInterval = 30 * time.Second
+
+// jobBudgetReasonMetadataKey is where checkJobBudget/blockJobForBudget
+// stash CheckBudget's rejection reason, surfaced by GetJob the same way
+// jobAttemptHistoryMetadataKey surfaces a retry's last error.
+const jobBudgetReasonMetadataKey = "budgetBlockedReason"
+
+// checkJobBudget estimates job's cost via its parser's EstimateCost and
+// checks it against j.costTracking's configured budget. It returns
+// (false, "") when costTracking is nil (budgets never configured) or the
+// parser/estimate can't be resolved - an estimation failure shouldn't itself
+// block a job that would otherwise be allowed to run. A Warn verdict is
+// surfaced to the frontend as a "budgetWarning" event but never blocks
+// dispatch.
+func (j *JobQueueServiceImpl) checkJobBudget(job *JobRecord) (blocked bool, reason string) {
+	if j.costTracking == nil {
+		return false, ""
+	}
+
+	parser, err := j.parserManager.GetParser(job.ParserID)
+	if err != nil {
+		return false, ""
+	}
+
+	filePath, _, err := j.lookupFile(job.FileID)
+	if err != nil {
+		return false, ""
+	}
+
+	estimatedCost, err := parser.EstimateCost(filePath)
+	if err != nil {
+		return false, ""
+	}
+
+	status := j.costTracking.CheckBudget(job.ParserID, estimatedCost)
+	if status.Warn {
+		j.emitEvent("budgetWarning", status)
+	}
+	if !status.Allowed {
+		return true, status.Reason
+	}
+	return false, ""
+}
+
+// blockJobForBudget marks job statusBlockedByBudget with reason recorded in
+// its metadata, and emits a "jobBlockedByBudget" event so the frontend can
+// surface it without polling GetQueueStatus.
+func (j *JobQueueServiceImpl) blockJobForBudget(job *JobRecord, reason string) error {
+	log.Printf("Job %s blocked by budget: %s", job.ID, reason)
+
+	if err := j.store.UpdateStatus(job.ID, statusBlockedByBudget, map[string]interface{}{
+		"errorMessage": reason,
+	}); err != nil {
+		return err
+	}
+	if err := j.store.UpdateMetadata(job.ID, map[string]interface{}{
+		jobBudgetReasonMetadataKey: reason,
+	}); err != nil {
+		log.Printf("Warning: failed to record budget block reason for job %s: %v", job.ID, err)
+	}
+
+	j.emitEvent("jobBlockedByBudget", map[string]interface{}{
+		"jobId":  job.ID,
+		"reason": reason,
+	})
+	return nil
+}
+
+// emitEvent emits a Wails event if SetContext has been called; a nil ctx
+// (the CLI path in runPipelineSpecCLI, or a JobQueueServiceImpl under test)
+// makes this a no-op rather than panicking.
+func (j *JobQueueServiceImpl) emitEvent(name string, data interface{}) {
+	if j.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(j.ctx, name, data)
+}
+
+// budgetGateSweeper sweeps every budgetGateSweepInterval for
+// blocked_by_budget jobs that would now pass checkJobBudget, until ctx is
+// cancelled.
+func (j *JobQueueServiceImpl) budgetGateSweeper(ctx context.Context) {
+	ticker := time.NewTicker(budgetGateSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.advanceBudgetGate()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// advanceBudgetGate lists every job the store knows about and flips any
+// blocked_by_budget job that checkJobBudget would now allow back to
+// "pending", so processJobQueue picks it up on the next claim. It's a
+// no-op if the active store doesn't support JobLister, same as
+// advanceScheduledRetries.
+func (j *JobQueueServiceImpl) advanceBudgetGate() {
+	if j.costTracking == nil {
+		return
+	}
+
+	lister, ok := j.store.(JobLister)
+	if !ok {
+		return
+	}
+
+	jobs, err := lister.ListAll()
+	if err != nil {
+		log.Printf("Warning: failed to list jobs for budget gate sweep: %v", err)
+		return
+	}
+
+	requeued := false
+	for _, job := range jobs {
+		if job.Status != statusBlockedByBudget {
+			continue
+		}
+
+		if blocked, _ := j.checkJobBudget(&job); blocked {
+			continue
+		}
+
+		if err := j.store.UpdateStatus(job.ID, "pending", nil); err != nil {
+			log.Printf("Warning: failed to requeue budget-blocked job %s: %v", job.ID, err)
+			continue
+		}
+		requeued = true
+	}
+
+	if requeued {
+		j.wakeNow()
+	}
+}
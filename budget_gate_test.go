@@ -0,0 +1,112 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newTestCostTrackingService builds a CostTrackingService backed by a
+// JSONCostStore under t.TempDir, bypassing NewCostTrackingService's
+// config-file/Convex-driven config loading so tests only exercise CheckBudget's
+// limit math against an explicit BudgetConfig.
+func newTestCostTrackingService(t *testing.T, budget BudgetConfig) *CostTrackingService {
+	t.Helper()
+
+	store, err := NewJSONCostStore(filepath.Join(t.TempDir(), "cost_data.json"))
+	if err != nil {
+		t.Fatalf("failed to open JSON cost store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return &CostTrackingService{store: store, budget: budget}
+}
+
+// TestCheckBudgetAllowsWhenUnderLimit verifies a job within every
+// configured limit is allowed without a warning.
+func TestCheckBudgetAllowsWhenUnderLimit(t *testing.T) {
+	s := newTestCostTrackingService(t, BudgetConfig{DailyLimitUSD: 10})
+
+	status := s.CheckBudget("transcription", 1.0)
+	if !status.Allowed {
+		t.Fatalf("expected job well under budget to be allowed, got reason %q", status.Reason)
+	}
+	if status.Warn {
+		t.Error("expected no warning for a job well under budget")
+	}
+}
+
+// TestCheckBudgetBlocksWhenDailyLimitExceeded verifies a job is blocked once
+// its estimated cost would push today's spend past DailyLimitUSD.
+func TestCheckBudgetBlocksWhenDailyLimitExceeded(t *testing.T) {
+	s := newTestCostTrackingService(t, BudgetConfig{DailyLimitUSD: 5})
+	s.RecordCost("transcription", 4.5, 60, "ref-1")
+
+	status := s.CheckBudget("transcription", 1.0)
+	if status.Allowed {
+		t.Fatal("expected job to be blocked once it would exceed the daily limit")
+	}
+	if status.Reason == "" {
+		t.Error("expected a non-empty reason when blocking a job")
+	}
+}
+
+// TestCheckBudgetBlocksWhenSessionLimitExceeded verifies SessionLimitUSD is
+// checked against the in-memory session cost, independent of the store.
+func TestCheckBudgetBlocksWhenSessionLimitExceeded(t *testing.T) {
+	s := newTestCostTrackingService(t, BudgetConfig{SessionLimitUSD: 1})
+	s.sessionCost = 0.9
+
+	status := s.CheckBudget("transcription", 0.5)
+	if status.Allowed {
+		t.Fatal("expected job to be blocked once it would exceed the session limit")
+	}
+}
+
+// TestCheckBudgetPerParserLimitIsIndependentOfOtherParsers verifies a
+// per-parser daily limit only counts that parser's own spend, not the
+// aggregate across parsers.
+func TestCheckBudgetPerParserLimitIsIndependentOfOtherParsers(t *testing.T) {
+	s := newTestCostTrackingService(t, BudgetConfig{
+		PerParserDailyLimitUSD: map[string]float64{"transcription": 2},
+	})
+	s.RecordCost("summary", 10, 0, "ref-1")
+
+	status := s.CheckBudget("transcription", 1.0)
+	if !status.Allowed {
+		t.Fatalf("expected transcription's own spend to be unaffected by summary's spend, got reason %q", status.Reason)
+	}
+
+	s.RecordCost("transcription", 1.5, 60, "ref-2")
+	status = s.CheckBudget("transcription", 1.0)
+	if status.Allowed {
+		t.Fatal("expected transcription to be blocked once its own spend would exceed its per-parser limit")
+	}
+}
+
+// TestCheckBudgetZeroLimitMeansUnlimited verifies a zero-valued limit never
+// blocks a job, matching defaultBudgetConfig's documented behavior.
+func TestCheckBudgetZeroLimitMeansUnlimited(t *testing.T) {
+	s := newTestCostTrackingService(t, defaultBudgetConfig())
+	s.RecordCost("transcription", 1_000_000, 0, "ref-1")
+
+	status := s.CheckBudget("transcription", 1_000_000)
+	if !status.Allowed {
+		t.Errorf("expected unlimited (zero) budget to never block, got reason %q", status.Reason)
+	}
+}
+
+// TestCheckBudgetWarnsNearThreshold verifies Warn is set once projected
+// spend crosses budgetWarnThreshold of the daily limit, while still
+// allowing the job.
+func TestCheckBudgetWarnsNearThreshold(t *testing.T) {
+	s := newTestCostTrackingService(t, BudgetConfig{DailyLimitUSD: 10})
+	s.RecordCost("transcription", 7.5, 0, "ref-1")
+
+	status := s.CheckBudget("transcription", 1.0)
+	if !status.Allowed {
+		t.Fatalf("expected job still under the daily limit to be allowed, got reason %q", status.Reason)
+	}
+	if !status.Warn {
+		t.Error("expected Warn once projected spend crosses budgetWarnThreshold of the daily limit")
+	}
+}
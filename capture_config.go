@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const captureConfigFile = "capture_config.json"
+
+// CaptureConfig controls how AudioService opens the PortAudio input stream:
+// sample rate, channel count, sample bit depth, and the PortAudio buffer
+// size. It is persisted to disk so the user's chosen device settings
+// survive restarts, the same way device selection does.
+type CaptureConfig struct {
+	SampleRate        float64 `json:"sampleRate"`
+	Channels          int     `json:"channels"`
+	BitDepth          int     `json:"bitDepth"`
+	FramesPerBuffer   int     `json:"framesPerBuffer"`
+	ResampleTo16kMono bool    `json:"resampleTo16kMono"`
+}
+
+// defaultCaptureConfig matches the hardcoded values AudioService used
+// before capture parameters became configurable.
+func defaultCaptureConfig() CaptureConfig {
+	return CaptureConfig{
+		SampleRate:      sampleRate,
+		Channels:        1,
+		BitDepth:        16,
+		FramesPerBuffer: 256,
+	}
+}
+
+// GetCaptureConfig returns the currently configured capture parameters.
+func (s *AudioService) GetCaptureConfig() CaptureConfig {
+	return s.captureConfig
+}
+
+// SetCaptureConfig validates and persists new capture parameters. They take
+// effect on the next StartRecording call; an in-progress recording is left
+// running with its existing settings.
+func (s *AudioService) SetCaptureConfig(cfg CaptureConfig) error {
+	if cfg.Channels != 1 && cfg.Channels != 2 {
+		return fmt.Errorf("unsupported channel count: %d (must be 1 or 2)", cfg.Channels)
+	}
+	if cfg.BitDepth != 16 && cfg.BitDepth != 32 {
+		return fmt.Errorf("unsupported bit depth: %d (must be 16 or 32)", cfg.BitDepth)
+	}
+	if cfg.SampleRate <= 0 {
+		return fmt.Errorf("sample rate must be positive")
+	}
+	if cfg.FramesPerBuffer <= 0 {
+		cfg.FramesPerBuffer = defaultCaptureConfig().FramesPerBuffer
+	}
+
+	s.captureConfig = cfg
+	return s.saveCaptureConfig()
+}
+
+// loadCaptureConfig reads the persisted capture config, falling back to
+// defaults if none has been saved yet.
+func (s *AudioService) loadCaptureConfig() {
+	s.captureConfig = defaultCaptureConfig()
+
+	data, err := os.ReadFile(captureConfigFile)
+	if err != nil {
+		return
+	}
+
+	var cfg CaptureConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Printf("Error parsing capture config file: %v\n", err)
+		return
+	}
+
+	s.captureConfig = cfg
+}
+
+// saveCaptureConfig writes the current capture config to disk.
+func (s *AudioService) saveCaptureConfig() error {
+	data, err := json.MarshalIndent(s.captureConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal capture config: %w", err)
+	}
+
+	if err := os.WriteFile(captureConfigFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write capture config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitState is a CircuitBreaker's current phase.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreakerWindow is how far back RecordResult looks when computing
+// the failure rate that trips the breaker.
+const circuitBreakerWindow = 1 * time.Minute
+
+// circuitBreakerMinRequests is the minimum number of requests in the
+// window before a failure rate is considered meaningful - otherwise a
+// single failed request on a cold start would trip the breaker.
+const circuitBreakerMinRequests = 5
+
+// circuitBreakerFailureRateThreshold is the fraction of requests in the
+// window that must fail for the breaker to open.
+const circuitBreakerFailureRateThreshold = 0.5
+
+// circuitBreakerCooldown is how long an open breaker waits before letting
+// a single half-open probe request through.
+const circuitBreakerCooldown = 30 * time.Second
+
+// circuitBreakerOutcome is one request's result, kept only long enough to
+// fall out of circuitBreakerWindow.
+type circuitBreakerOutcome struct {
+	at      time.Time
+	success bool
+}
+
+// CircuitBreaker implements the closed/open/half-open pattern per
+// endpoint: Allow() gates whether a caller should even attempt a request,
+// and RecordResult feeds back what happened so the breaker can trip (or
+// recover) on its own. httpxDoWithRetry is the only caller in this repo,
+// but it's a standalone type so ConvexClient and the OpenAI-backed parsers
+// can share the same circuitBreakerRegistry.
+type CircuitBreaker struct {
+	mutex sync.Mutex
+
+	// endpoint labels circuitBreakerOpenedTotal when this breaker trips -
+	// purely for metrics, never used to key anything.
+	endpoint string
+
+	state         circuitState
+	openedAt      time.Time
+	outcomes      []circuitBreakerOutcome
+	probeInFlight bool
+}
+
+// Allow reports whether a request should be attempted right now. A closed
+// breaker always allows; an open breaker allows nothing until
+// circuitBreakerCooldown has passed, at which point it allows exactly one
+// half-open probe at a time.
+func (b *CircuitBreaker) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	case circuitOpen:
+		if time.Since(b.openedAt) < circuitBreakerCooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		b.probeInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// RecordResult feeds back a request's outcome. A half-open probe that
+// succeeds closes the breaker; one that fails reopens it for another
+// cooldown. A closed breaker trips to open once circuitBreakerMinRequests
+// have landed in circuitBreakerWindow and at least
+// circuitBreakerFailureRateThreshold of them failed.
+func (b *CircuitBreaker) RecordResult(success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probeInFlight = false
+		if success {
+			b.state = circuitClosed
+			b.outcomes = nil
+		} else {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+			circuitBreakerOpenedTotal.WithLabelValues(b.endpoint).Inc()
+		}
+		return
+	}
+
+	now := time.Now()
+	b.outcomes = append(b.outcomes, circuitBreakerOutcome{at: now, success: success})
+
+	cutoff := now.Add(-circuitBreakerWindow)
+	kept := b.outcomes[:0]
+	failures := 0
+	for _, o := range b.outcomes {
+		if o.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, o)
+		if !o.success {
+			failures++
+		}
+	}
+	b.outcomes = kept
+
+	if len(b.outcomes) >= circuitBreakerMinRequests {
+		if float64(failures)/float64(len(b.outcomes)) >= circuitBreakerFailureRateThreshold {
+			b.state = circuitOpen
+			b.openedAt = now
+			b.outcomes = nil
+			circuitBreakerOpenedTotal.WithLabelValues(b.endpoint).Inc()
+		}
+	}
+}
+
+// State returns the breaker's current phase, for GetQueueStatus and
+// similar read-only reporting.
+func (b *CircuitBreaker) State() circuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+// circuitBreakerRegistry hands out one CircuitBreaker per endpoint name,
+// creating it on first use, so every caller sharing the same endpoint
+// (e.g. every ConvexClient method calling "jobs:create") trips the same
+// breaker.
+type circuitBreakerRegistry struct {
+	mutex    sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+var globalCircuitBreakers = &circuitBreakerRegistry{
+	breakers: make(map[string]*CircuitBreaker),
+}
+
+// breakerFor returns the CircuitBreaker for endpoint, creating it if this
+// is the first time it's been requested.
+func (r *circuitBreakerRegistry) breakerFor(endpoint string) *CircuitBreaker {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	b, ok := r.breakers[endpoint]
+	if !ok {
+		b = &CircuitBreaker{endpoint: endpoint}
+		r.breakers[endpoint] = b
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package main
+
+import "testing"
+
+// TestCircuitBreakerOpensAfterThreshold verifies a breaker trips to open
+// once circuitBreakerMinRequests have landed and the failure rate reaches
+// circuitBreakerFailureRateThreshold, and that it refuses requests while
+// open.
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := &CircuitBreaker{endpoint: "test"}
+
+	for i := 0; i < circuitBreakerMinRequests; i++ {
+		if !b.Allow() {
+			t.Fatalf("expected closed breaker to allow request %d", i)
+		}
+		b.RecordResult(false)
+	}
+
+	if b.State() != circuitOpen {
+		t.Fatalf("expected breaker to open after %d consecutive failures, got state %v", circuitBreakerMinRequests, b.State())
+	}
+	if b.Allow() {
+		t.Error("expected open breaker to refuse requests before cooldown elapses")
+	}
+}
+
+// TestCircuitBreakerStaysClosedBelowMinRequests verifies a breaker doesn't
+// trip on a handful of failures before circuitBreakerMinRequests is reached.
+func TestCircuitBreakerStaysClosedBelowMinRequests(t *testing.T) {
+	b := &CircuitBreaker{endpoint: "test"}
+
+	for i := 0; i < circuitBreakerMinRequests-1; i++ {
+		b.Allow()
+		b.RecordResult(false)
+	}
+
+	if b.State() != circuitClosed {
+		t.Errorf("expected breaker to stay closed below circuitBreakerMinRequests, got state %v", b.State())
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbeCloses verifies that once a breaker is
+// open, a successful half-open probe closes it again.
+func TestCircuitBreakerHalfOpenProbeCloses(t *testing.T) {
+	b := &CircuitBreaker{
+		endpoint: "test",
+		state:    circuitHalfOpen,
+	}
+
+	if !b.Allow() {
+		t.Fatal("expected half-open breaker to allow its one probe")
+	}
+	if b.Allow() {
+		t.Error("expected half-open breaker to refuse a second concurrent probe")
+	}
+
+	b.RecordResult(true)
+
+	if b.State() != circuitClosed {
+		t.Errorf("expected successful probe to close the breaker, got state %v", b.State())
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbeFailureReopens verifies a failed half-open
+// probe reopens the breaker rather than closing it.
+func TestCircuitBreakerHalfOpenProbeFailureReopens(t *testing.T) {
+	b := &CircuitBreaker{
+		endpoint: "test",
+		state:    circuitHalfOpen,
+	}
+
+	b.Allow()
+	b.RecordResult(false)
+
+	if b.State() != circuitOpen {
+		t.Errorf("expected failed probe to reopen the breaker, got state %v", b.State())
+	}
+}
+
+// TestCircuitBreakerRegistrySharesBreakerPerEndpoint verifies breakerFor
+// returns the same instance for repeated calls with the same endpoint, and
+// a distinct one for a different endpoint.
+func TestCircuitBreakerRegistrySharesBreakerPerEndpoint(t *testing.T) {
+	registry := &circuitBreakerRegistry{breakers: make(map[string]*CircuitBreaker)}
+
+	a1 := registry.breakerFor("jobs:create")
+	a2 := registry.breakerFor("jobs:create")
+	b1 := registry.breakerFor("jobs:update")
+
+	if a1 != a2 {
+		t.Error("expected repeated calls for the same endpoint to return the same breaker")
+	}
+	if a1 == b1 {
+		t.Error("expected different endpoints to get different breakers")
+	}
+}
+
+// TestRetryPolicyDelayForAttemptGrowsAndCaps verifies delayForAttempt grows
+// roughly geometrically by Multiplier and never exceeds MaxDelay, even with
+// jitter applied.
+func TestRetryPolicyDelayForAttemptGrowsAndCaps(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    5,
+		InitialDelay:   0,
+		Multiplier:     2.0,
+		MaxDelay:       0,
+		JitterFraction: 0,
+	}
+	p.InitialDelay = 100_000_000 // 100ms, avoid time import churn
+	p.MaxDelay = 1_000_000_000   // 1s
+
+	prev := p.delayForAttempt(1)
+	for attempt := 2; attempt <= 4; attempt++ {
+		d := p.delayForAttempt(attempt)
+		if d < prev {
+			t.Errorf("expected delay to grow with attempt, attempt %d delay %v <= previous %v", attempt, d, prev)
+		}
+		prev = d
+	}
+
+	if d := p.delayForAttempt(10); d > p.MaxDelay {
+		t.Errorf("expected delay to be capped at MaxDelay %v, got %v", p.MaxDelay, d)
+	}
+}
+
+// TestRetryPolicyDelayForAttemptNeverNegative verifies jitter never drives
+// the computed delay below zero.
+func TestRetryPolicyDelayForAttemptNeverNegative(t *testing.T) {
+	p := defaultRetryPolicy()
+	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
+		if d := p.delayForAttempt(attempt); d < 0 {
+			t.Errorf("delayForAttempt(%d) returned negative duration %v", attempt, d)
+		}
+	}
+}
+
+// TestRetryableStatusCodes verifies the known transient status codes are
+// retryable and a client error isn't mistakenly included.
+func TestRetryableStatusCodes(t *testing.T) {
+	for _, code := range []int{429, 502, 503, 504} {
+		if !retryableStatusCodes[code] {
+			t.Errorf("expected status %d to be retryable", code)
+		}
+	}
+	if retryableStatusCodes[400] {
+		t.Error("expected 400 to not be retryable")
+	}
+}
@@ -2,13 +2,17 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"time"
 )
 
+var convexLog = GetFacility("convex")
+
 // ConvexClient is a client for the Convex API.
 type ConvexClient struct {
 	deploymentURL string
@@ -28,7 +32,7 @@ func NewConvexClient() (*ConvexClient, error) {
 	if adminKey == "" {
 		// This should be set in production environments.
 		// For local dev, you might get it from the Convex dashboard.
-		fmt.Println("WARNING: CONVEX_ADMIN_KEY environment variable not set.")
+		convexLog.Debugln("WARNING: CONVEX_ADMIN_KEY environment variable not set.")
 	}
 
 	return &ConvexClient{
@@ -40,6 +44,16 @@ func NewConvexClient() (*ConvexClient, error) {
 
 // CallMutation calls a mutation on the Convex API.
 func (c *ConvexClient) CallMutation(functionName string, args map[string]interface{}) (interface{}, error) {
+	start := time.Now()
+	value, err := c.callMutation(functionName, args)
+	convexCallDurationSeconds.WithLabelValues(functionName).Observe(time.Since(start).Seconds())
+	if err != nil {
+		convexCallErrorsTotal.WithLabelValues(functionName).Inc()
+	}
+	return value, err
+}
+
+func (c *ConvexClient) callMutation(functionName string, args map[string]interface{}) (interface{}, error) {
 	url := fmt.Sprintf("%s/api/mutation", c.deploymentURL)
 
 	payload := map[string]interface{}{
@@ -53,29 +67,38 @@ func (c *ConvexClient) CallMutation(functionName string, args map[string]interfa
 		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if c.adminKey != "" {
-		req.Header.Set("Authorization", "Convex "+c.adminKey)
-	}
-
-	resp, err := c.client.Do(req)
+	// Retried (with backoff + jitter) and circuit-broken per Convex function
+	// name, so a struggling mutation doesn't also take down unrelated ones.
+	body, err := httpxDoWithRetry(context.Background(), "convex:"+functionName, defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if c.adminKey != "" {
+			req.Header.Set("Authorization", "Convex "+c.adminKey)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+
+		return resp.StatusCode, respBody, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var result map[string]interface{}
@@ -93,6 +116,16 @@ func (c *ConvexClient) CallMutation(functionName string, args map[string]interfa
 
 // CallQuery calls a query on the Convex API.
 func (c *ConvexClient) CallQuery(functionName string, args map[string]interface{}) (interface{}, error) {
+	start := time.Now()
+	value, err := c.callQuery(functionName, args)
+	convexCallDurationSeconds.WithLabelValues(functionName).Observe(time.Since(start).Seconds())
+	if err != nil {
+		convexCallErrorsTotal.WithLabelValues(functionName).Inc()
+	}
+	return value, err
+}
+
+func (c *ConvexClient) callQuery(functionName string, args map[string]interface{}) (interface{}, error) {
 	url := fmt.Sprintf("%s/api/query", c.deploymentURL)
 
 	payload := map[string]interface{}{
@@ -106,29 +139,36 @@ func (c *ConvexClient) CallQuery(functionName string, args map[string]interface{
 		return nil, fmt.Errorf("failed to marshal JSON payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	body, err := httpxDoWithRetry(context.Background(), "convex:"+functionName, defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	if c.adminKey != "" {
-		req.Header.Set("Authorization", "Convex "+c.adminKey)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		if c.adminKey != "" {
+			req.Header.Set("Authorization", "Convex "+c.adminKey)
+		}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var result map[string]interface{}
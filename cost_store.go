@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const costStoreConfigFile = "cost_store_config.json"
+const defaultLocalCostStorePath = "cost_data.json"
+const defaultCostJournalPath = "cost_data"
+const defaultLocalCostDBPath = "voiceworker_costs.db"
+
+// CostStoreBackend selects which CostStore implementation
+// CostTrackingService persists transcription cost entries through.
+type CostStoreBackend string
+
+const (
+	CostStoreBackendJSON   CostStoreBackend = "json"
+	CostStoreBackendNDJSON CostStoreBackend = "ndjson"
+	CostStoreBackendSQLite CostStoreBackend = "sqlite"
+)
+
+// CostStoreConfig selects and configures the cost persistence backend. It is
+// persisted to disk the same way JobStoreConfig is, so the choice survives
+// restarts.
+type CostStoreConfig struct {
+	Backend CostStoreBackend `json:"backend"`
+	Path    string           `json:"path"`
+}
+
+// defaultCostStoreConfig matches the behavior CostTrackingService had before
+// its backend became pluggable: a single cost_data.json, rewritten whole on
+// every write.
+func defaultCostStoreConfig() CostStoreConfig {
+	return CostStoreConfig{
+		Backend: CostStoreBackendJSON,
+		Path:    defaultLocalCostStorePath,
+	}
+}
+
+// loadCostStoreConfig reads the persisted cost store config, falling back to
+// defaultCostStoreConfig if none has been saved yet or it fails to parse.
+func loadCostStoreConfig() CostStoreConfig {
+	cfg := defaultCostStoreConfig()
+
+	data, err := os.ReadFile(costStoreConfigFile)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Printf("Error parsing cost store config file: %v\n", err)
+		return defaultCostStoreConfig()
+	}
+	if cfg.Path == "" {
+		cfg.Path = defaultCostStoreConfig().Path
+	}
+
+	return cfg
+}
+
+// saveCostStoreConfig persists cfg so the backend choice survives a restart.
+func saveCostStoreConfig(cfg CostStoreConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cost store config: %w", err)
+	}
+	return os.WriteFile(costStoreConfigFile, data, 0644)
+}
+
+// CostStore persists CostEntry records for CostTrackingService. JSONCostStore
+// is the default, matching the original single cost_data.json;
+// NDJSONCostStore is an append-only journal with periodic snapshotting for
+// deployments recording many transcriptions; SQLiteCostStore is an embedded
+// SQLite alternative for range queries over large histories.
+// CostTrackingService only ever talks to this interface, so switching
+// backends is a config change, not a code change.
+type CostStore interface {
+	// Record durably appends a single transcription cost entry.
+	Record(entry CostEntry) error
+
+	// Query returns every entry recorded in [from, to), so GetDailyCosts and
+	// GetHourlyCosts can serve arbitrary ranges. NDJSONCostStore reads only
+	// the monthly files overlapping the range and SQLiteCostStore uses an
+	// indexed lookup; JSONCostStore filters the full history it holds in
+	// memory.
+	Query(from, to time.Time) ([]CostEntry, error)
+
+	Close() error
+}
+
+// CostLister is implemented by CostStore backends that can enumerate every
+// entry they hold, so migrateCostStore doesn't need an unbounded Query
+// range to mean the same thing across backends.
+type CostLister interface {
+	ListAll() ([]CostEntry, error)
+}
+
+// NewCostStore builds the CostStore selected by the persisted
+// CostStoreConfig.
+func NewCostStore(cfg CostStoreConfig) (CostStore, error) {
+	switch cfg.Backend {
+	case CostStoreBackendJSON, "":
+		path := cfg.Path
+		if path == "" {
+			path = defaultLocalCostStorePath
+		}
+		return NewJSONCostStore(path)
+	case CostStoreBackendNDJSON:
+		path := cfg.Path
+		if path == "" {
+			path = defaultCostJournalPath
+		}
+		return NewNDJSONCostStore(path)
+	case CostStoreBackendSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = defaultLocalCostDBPath
+		}
+		return NewSQLiteCostStore(path)
+	default:
+		return nil, fmt.Errorf("unknown cost store backend: %q", cfg.Backend)
+	}
+}
+
+// migrateCostStore copies every entry from src to dst and reports how many
+// were copied. It's meant for a one-time switch between backends - callers
+// decide whether to keep or retire src afterward.
+func migrateCostStore(src, dst CostStore) (int, error) {
+	lister, ok := src.(CostLister)
+	if !ok {
+		return 0, fmt.Errorf("source cost store does not support listing entries for migration")
+	}
+
+	entries, err := lister.ListAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list entries from source cost store: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := dst.Record(entry); err != nil {
+			return 0, fmt.Errorf("failed to migrate entry %s: %w", entry.TranscriptID, err)
+		}
+	}
+
+	return len(entries), nil
+}
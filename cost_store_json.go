@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JSONCostStore is the original CostTrackingService backend: every entry
+// ever recorded, held in memory and rewritten to a single JSON file on every
+// write. Writes go through writeFileAtomic (temp file + fsync + rename) so a
+// crash mid-write can no longer leave a truncated or partially-written
+// cost_data.json behind - the previous implementation wrote directly to the
+// destination path.
+type JSONCostStore struct {
+	mutex sync.Mutex
+	path  string
+	data  CostData
+}
+
+// NewJSONCostStore opens (creating if necessary) the JSON cost file at path.
+func NewJSONCostStore(path string) (*JSONCostStore, error) {
+	s := &JSONCostStore{
+		path: path,
+		data: CostData{
+			Entries:     make([]CostEntry, 0),
+			DailyTotals: make(map[string]DailyCost),
+		},
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *JSONCostStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cost data file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &s.data); err != nil {
+		return fmt.Errorf("failed to parse cost data file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *JSONCostStore) Record(entry CostEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data.Entries = append(s.data.Entries, entry)
+	s.data.LastUpdated = time.Now()
+	s.updateDailyTotals(entry)
+
+	encoded, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cost data: %w", err)
+	}
+
+	return writeFileAtomic(s.path, encoded, 0644)
+}
+
+// updateDailyTotals keeps CostData.DailyTotals current so cost_data.json's
+// on-disk shape stays identical to what it was before the store became
+// pluggable, for anything outside this process that still reads the file
+// directly.
+func (s *JSONCostStore) updateDailyTotals(entry CostEntry) {
+	dateKey := entry.Timestamp.Format("2006-01-02")
+
+	daily, exists := s.data.DailyTotals[dateKey]
+	if !exists {
+		daily = DailyCost{Date: dateKey}
+	}
+
+	daily.TotalCost += entry.Cost
+	daily.TotalDuration += entry.DurationSec
+	daily.TranscriptCount++
+
+	s.data.DailyTotals[dateKey] = daily
+}
+
+func (s *JSONCostStore) Query(from, to time.Time) ([]CostEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []CostEntry
+	for _, entry := range s.data.Entries {
+		if !entry.Timestamp.Before(from) && entry.Timestamp.Before(to) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (s *JSONCostStore) ListAll() ([]CostEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entries := make([]CostEntry, len(s.data.Entries))
+	copy(entries, s.data.Entries)
+	return entries, nil
+}
+
+func (s *JSONCostStore) Close() error {
+	return nil
+}
+
+// writeFileAtomic writes data to path without ever leaving a truncated or
+// partially-written file there if the process crashes or loses power
+// mid-write: it writes to a temp file in the same directory (so the final
+// rename is on the same filesystem and therefore atomic), fsyncs it, then
+// renames over path.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to fsync temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set permissions on temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename temp file into place: %w", err)
+	}
+
+	return nil
+}
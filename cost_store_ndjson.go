@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ndjsonCompactionThreshold is how many records this process appends to a
+// rotation file before compactLocked rewrites it as a single well-formed
+// file, dropping any torn or malformed lines it has picked up.
+const ndjsonCompactionThreshold = 5000
+
+// NDJSONCostStore is an append-only journal of CostEntry records, one JSON
+// object per line, rotated monthly into separate files so no single file
+// grows unbounded across the lifetime of a deployment. Appending a line is
+// inherently safe against a torn write on power loss: a partial last line is
+// simply skipped by the reader, unlike JSONCostStore's whole-file rewrite
+// which risks losing everything recorded so far if the write is
+// interrupted.
+type NDJSONCostStore struct {
+	mutex        sync.Mutex
+	dir          string
+	prefix       string
+	appendCounts map[string]int
+}
+
+// NewNDJSONCostStore opens (creating if necessary) the directory holding the
+// monthly rotation files. pathPrefix is the same "cost_data" base used to
+// name each rotation file cost_data.YYYY-MM.ndjson.
+func NewNDJSONCostStore(pathPrefix string) (*NDJSONCostStore, error) {
+	dir := filepath.Dir(pathPrefix)
+	if dir == "" {
+		dir = "."
+	}
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cost journal directory: %w", err)
+		}
+	}
+
+	return &NDJSONCostStore{
+		dir:          dir,
+		prefix:       filepath.Base(pathPrefix),
+		appendCounts: make(map[string]int),
+	}, nil
+}
+
+// rotationPath returns the journal file t's month rotates into.
+func (s *NDJSONCostStore) rotationPath(t time.Time) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s.%s.ndjson", s.prefix, t.Format("2006-01")))
+}
+
+func (s *NDJSONCostStore) Record(entry CostEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := s.rotationPath(entry.Timestamp)
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cost entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open cost journal %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(encoded, '\n')); err != nil {
+		return fmt.Errorf("failed to append cost entry: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync cost journal: %w", err)
+	}
+
+	s.appendCounts[path]++
+	if s.appendCounts[path] >= ndjsonCompactionThreshold {
+		if err := s.compactLocked(path); err != nil {
+			costLog.Debugf("Warning: failed to compact cost journal %s: %v\n", path, err)
+		}
+		s.appendCounts[path] = 0
+	}
+
+	return nil
+}
+
+// compactLocked rewrites path's rotation file through writeFileAtomic,
+// dropping nothing (every appended entry is already live - there's no
+// superseding update in this model, unlike e.g. a key-value journal) but
+// collapsing it to a single well-formed file, which also corrects for any
+// torn last line left by a crash mid-append. Caller must hold s.mutex.
+func (s *NDJSONCostStore) compactLocked(path string) error {
+	entries, err := readNDJSON(path)
+	if err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	for _, entry := range entries {
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cost entry during compaction: %w", err)
+		}
+		buf.Write(encoded)
+		buf.WriteByte('\n')
+	}
+
+	return writeFileAtomic(path, []byte(buf.String()), 0644)
+}
+
+// readNDJSON reads every well-formed line of path, skipping a truncated
+// final line (the signature of a write that was interrupted mid-append) and
+// any line that fails to parse rather than failing the whole read.
+func readNDJSON(path string) ([]CostEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open cost journal %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []CostEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry CostEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			costLog.Debugf("Warning: skipping malformed cost journal line in %s: %v\n", path, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// rotationsBetween returns every rotation path whose month overlaps
+// [from, to).
+func (s *NDJSONCostStore) rotationsBetween(from, to time.Time) []string {
+	var paths []string
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(to); month = month.AddDate(0, 1, 0) {
+		paths = append(paths, s.rotationPath(month))
+	}
+	return paths
+}
+
+func (s *NDJSONCostStore) Query(from, to time.Time) ([]CostEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []CostEntry
+	for _, path := range s.rotationsBetween(from, to) {
+		entries, err := readNDJSON(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.Timestamp.Before(from) && entry.Timestamp.Before(to) {
+				result = append(result, entry)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result, nil
+}
+
+// ListAll reads every rotation file found in the journal directory, for
+// migrateCostStore's one-time backend switch.
+func (s *NDJSONCostStore) ListAll() ([]CostEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	matches, err := filepath.Glob(filepath.Join(s.dir, s.prefix+".*.ndjson"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cost journal rotations: %w", err)
+	}
+	sort.Strings(matches)
+
+	var all []CostEntry
+	for _, path := range matches {
+		entries, err := readNDJSON(path)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+
+	return all, nil
+}
+
+func (s *NDJSONCostStore) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// costStoreSchema creates the cost_entries table on first use.
+const costStoreSchema = `
+CREATE TABLE IF NOT EXISTS cost_entries (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	transcript_id TEXT NOT NULL,
+	timestamp     INTEGER NOT NULL,
+	duration_sec  REAL NOT NULL,
+	cost          REAL NOT NULL,
+	parser_id     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS cost_entries_timestamp_idx ON cost_entries(timestamp);
+`
+
+// SQLiteCostStore is an embedded, file-free-format CostStore backed by a
+// SQLite database via the pure-Go modernc.org/sqlite driver (no cgo, the
+// same convention LocalJobStore uses), so Query can serve an arbitrary
+// [from, to) range with an indexed lookup instead of a full scan.
+type SQLiteCostStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteCostStore opens (creating if necessary) the SQLite database at
+// path and ensures its schema exists.
+func NewSQLiteCostStore(path string) (*SQLiteCostStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cost store at %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(costStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cost store schema: %w", err)
+	}
+
+	return &SQLiteCostStore{db: db}, nil
+}
+
+func (s *SQLiteCostStore) Record(entry CostEntry) error {
+	_, err := s.db.Exec(
+		`INSERT INTO cost_entries (transcript_id, timestamp, duration_sec, cost, parser_id) VALUES (?, ?, ?, ?, ?)`,
+		entry.TranscriptID, entry.Timestamp.UnixMilli(), entry.DurationSec, entry.Cost, entry.ParserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert cost entry: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteCostStore) Query(from, to time.Time) ([]CostEntry, error) {
+	rows, err := s.db.Query(
+		`SELECT transcript_id, timestamp, duration_sec, cost, parser_id FROM cost_entries WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp ASC`,
+		from.UnixMilli(), to.UnixMilli(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cost entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCostEntries(rows)
+}
+
+func (s *SQLiteCostStore) ListAll() ([]CostEntry, error) {
+	rows, err := s.db.Query(`SELECT transcript_id, timestamp, duration_sec, cost, parser_id FROM cost_entries ORDER BY timestamp ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cost entries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCostEntries(rows)
+}
+
+func scanCostEntries(rows *sql.Rows) ([]CostEntry, error) {
+	var entries []CostEntry
+	for rows.Next() {
+		var entry CostEntry
+		var timestampMs int64
+		if err := rows.Scan(&entry.TranscriptID, &timestampMs, &entry.DurationSec, &entry.Cost, &entry.ParserID); err != nil {
+			return nil, fmt.Errorf("failed to scan cost entry row: %w", err)
+		}
+		entry.Timestamp = time.UnixMilli(timestampMs)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *SQLiteCostStore) Close() error {
+	return s.db.Close()
+}
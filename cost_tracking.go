@@ -1,11 +1,9 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"log"
 	"math"
-	"os"
-	"path/filepath"
 	"time"
 )
 
@@ -13,26 +11,35 @@ const (
 	// OpenAI Whisper API pricing: $0.006 per minute (rounded to nearest second)
 	WHISPER_COST_PER_MINUTE = 0.006
 	WHISPER_COST_PER_SECOND = WHISPER_COST_PER_MINUTE / 60.0
-	COST_DATA_FILE = "cost_data.json"
+	COST_DATA_FILE          = "cost_data.json"
 )
 
+var costLog = GetFacility("cost")
+
 // CostEntry represents a single transcription cost entry
 type CostEntry struct {
 	Timestamp    time.Time `json:"timestamp"`
 	DurationSec  float64   `json:"duration_seconds"`
 	Cost         float64   `json:"cost"`
 	TranscriptID string    `json:"transcript_id"`
+
+	// ParserID is which parser incurred this cost (e.g. "transcription"),
+	// so CheckBudget can enforce PerParserDailyLimitUSD. Empty for entries
+	// recorded before per-parser budgets existed.
+	ParserID string `json:"parser_id,omitempty"`
 }
 
 // DailyCost represents aggregated costs for a specific day
 type DailyCost struct {
-	Date           string  `json:"date"`
-	TotalCost      float64 `json:"total_cost"`
-	TotalDuration  float64 `json:"total_duration_seconds"`
-	TranscriptCount int    `json:"transcript_count"`
+	Date            string  `json:"date"`
+	TotalCost       float64 `json:"total_cost"`
+	TotalDuration   float64 `json:"total_duration_seconds"`
+	TranscriptCount int     `json:"transcript_count"`
 }
 
-// CostData holds all cost tracking information
+// CostData is JSONCostStore's on-disk shape - kept here (rather than in
+// cost_store_json.go) since it's also what a pre-CostStore cost_data.json
+// unmarshals into for the one-time migration in NewCostTrackingService.
 type CostData struct {
 	Entries       []CostEntry          `json:"entries"`
 	DailyTotals   map[string]DailyCost `json:"daily_totals"`
@@ -43,37 +50,109 @@ type CostData struct {
 	LastUpdated   time.Time            `json:"last_updated"`
 }
 
-// CostTrackingService manages transcription cost tracking
+// CostTrackingService manages transcription cost tracking. Persistence goes
+// through a pluggable CostStore (see cost_store.go) - this service only
+// keeps the in-memory session counters (SessionCost, SessionStart) that
+// reset every run, plus TotalCost/TotalDuration summed from the store at
+// startup.
 type CostTrackingService struct {
-	data     CostData
-	dataFile string
+	store         CostStore
+	convexClient  *ConvexClient
+	budget        BudgetConfig
+	sessionStart  time.Time
+	sessionCost   float64
+	totalCost     float64
+	totalDuration float64
+	totalCount    int
 }
 
-// NewCostTrackingService creates a new cost tracking service
-func NewCostTrackingService() *CostTrackingService {
-	service := &CostTrackingService{
-		dataFile: COST_DATA_FILE,
-		data: CostData{
-			Entries:      make([]CostEntry, 0),
-			DailyTotals:  make(map[string]DailyCost),
-			SessionStart: time.Now(),
-			SessionCost:  0.0,
-			TotalCost:    0.0,
-			TotalDuration: 0.0,
-			LastUpdated:  time.Now(),
-		},
+// NewCostTrackingService creates a new cost tracking service, opening the
+// CostStore selected by the persisted CostStoreConfig and the BudgetConfig
+// selected by loadBudgetConfig. If that config selects a non-default
+// backend and a legacy cost_data.json still exists alongside it, its
+// entries are migrated into the new backend once and the legacy file is
+// left in place (not deleted) as a backup. convexClient may be nil, in
+// which case budgets are local-file-only.
+func NewCostTrackingService(convexClient *ConvexClient) *CostTrackingService {
+	cfg := loadCostStoreConfig()
+
+	store, err := NewCostStore(cfg)
+	if err != nil {
+		log.Printf("Warning: failed to open cost store (%v), falling back to default JSON backend", err)
+		store, err = NewCostStore(defaultCostStoreConfig())
+		if err != nil {
+			log.Fatalf("failed to open fallback cost store: %v", err)
+		}
 	}
 
-	// Load existing data if file exists
-	service.loadData()
+	if cfg.Backend != CostStoreBackendJSON && cfg.Backend != "" {
+		migrateLegacyCostData(store)
+	}
 
-	// Reset session data for new session
-	service.data.SessionStart = time.Now()
-	service.data.SessionCost = 0.0
+	service := &CostTrackingService{
+		store:        store,
+		convexClient: convexClient,
+		budget:       loadBudgetConfig(convexClient),
+		sessionStart: time.Now(),
+	}
+	service.loadTotals()
 
 	return service
 }
 
+// migrateLegacyCostData is the one-time migration this service runs when it
+// opens a non-JSON backend for the first time: any entries recorded under
+// the old whole-file cost_data.json (from before the backend became
+// pluggable, or from a prior JSON-backend run) are copied into store so
+// switching backends doesn't lose history.
+func migrateLegacyCostData(store CostStore) {
+	// Once store holds any entries the migration has already run, so the
+	// legacy file kept as a backup isn't copied in again on every startup.
+	if lister, ok := store.(CostLister); ok {
+		if existing, err := lister.ListAll(); err == nil && len(existing) > 0 {
+			return
+		}
+	}
+
+	legacy, err := NewJSONCostStore(COST_DATA_FILE)
+	if err != nil {
+		costLog.Debugf("No legacy cost data to migrate: %v\n", err)
+		return
+	}
+	defer legacy.Close()
+
+	entries, err := legacy.ListAll()
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	migrated, err := migrateCostStore(legacy, store)
+	if err != nil {
+		log.Printf("Warning: failed to migrate legacy cost data: %v", err)
+		return
+	}
+	if migrated > 0 {
+		costLog.Debugf("Migrated %d legacy cost entries from %s into the configured cost store\n", migrated, COST_DATA_FILE)
+	}
+}
+
+// loadTotals sums every entry the store already holds into this session's
+// running totals, the same bootstrapping loadData used to do by reading
+// cost_data.json directly.
+func (s *CostTrackingService) loadTotals() {
+	entries, err := s.store.Query(time.Time{}, time.Now().AddDate(100, 0, 0))
+	if err != nil {
+		costLog.Debugf("Error loading cost totals: %v\n", err)
+		return
+	}
+
+	for _, entry := range entries {
+		s.totalCost += entry.Cost
+		s.totalDuration += entry.DurationSec
+		s.totalCount++
+	}
+}
+
 // CalculateCost calculates the cost for a given duration in seconds
 func (s *CostTrackingService) CalculateCost(durationSeconds float64) float64 {
 	// OpenAI rounds to nearest second, so we round up any fraction
@@ -81,165 +160,224 @@ func (s *CostTrackingService) CalculateCost(durationSeconds float64) float64 {
 	return roundedSeconds * WHISPER_COST_PER_SECOND
 }
 
-// RecordTranscription records a new transcription cost
+// RecordTranscription records a new transcription cost. It's a thin wrapper
+// over RecordCost for the transcription parser specifically, kept around
+// so existing call sites don't need to start passing a parser ID.
 func (s *CostTrackingService) RecordTranscription(durationSeconds float64, transcriptID string) float64 {
-	cost := s.CalculateCost(durationSeconds)
+	return s.RecordCost("transcription", s.CalculateCost(durationSeconds), durationSeconds, transcriptID)
+}
 
+// RecordCost records a cost entry incurred by parserId. refID identifies
+// what was processed (a transcript ID, a file path, etc.) for the
+// TranscriptID field, which predates per-parser costs and is reused as a
+// general reference regardless of parser.
+func (s *CostTrackingService) RecordCost(parserId string, cost float64, durationSeconds float64, refID string) float64 {
 	entry := CostEntry{
 		Timestamp:    time.Now(),
 		DurationSec:  durationSeconds,
 		Cost:         cost,
-		TranscriptID: transcriptID,
+		TranscriptID: refID,
+		ParserID:     parserId,
 	}
 
-	// Add to entries
-	s.data.Entries = append(s.data.Entries, entry)
-
-	// Update totals
-	s.data.SessionCost += cost
-	s.data.TotalCost += cost
-	s.data.TotalDuration += durationSeconds
-	s.data.LastUpdated = time.Now()
-
-	// Update daily totals
-	s.updateDailyTotals(entry)
-
-	// Save to file
-	s.saveData()
-
-	fmt.Printf("Transcription cost recorded: $%.4f (%.2f seconds)\n", cost, durationSeconds)
-	return cost
-}
-
-// updateDailyTotals updates the daily cost aggregation
-func (s *CostTrackingService) updateDailyTotals(entry CostEntry) {
-	dateKey := entry.Timestamp.Format("2006-01-02")
-
-	daily, exists := s.data.DailyTotals[dateKey]
-	if !exists {
-		daily = DailyCost{
-			Date:           dateKey,
-			TotalCost:      0.0,
-			TotalDuration:  0.0,
-			TranscriptCount: 0,
-		}
+	if err := s.store.Record(entry); err != nil {
+		costLog.Debugf("Error recording cost entry: %v\n", err)
 	}
 
-	daily.TotalCost += entry.Cost
-	daily.TotalDuration += entry.DurationSec
-	daily.TranscriptCount++
+	s.sessionCost += cost
+	s.totalCost += cost
+	s.totalDuration += durationSeconds
+	s.totalCount++
 
-	s.data.DailyTotals[dateKey] = daily
+	costLog.Debugf("Cost recorded for %s: $%.4f (%.2f seconds)\n", parserId, cost, durationSeconds)
+	return cost
 }
 
 // GetSessionCost returns the current session cost
 func (s *CostTrackingService) GetSessionCost() float64 {
-	return s.data.SessionCost
+	return s.sessionCost
 }
 
 // GetTotalCost returns the total accumulated cost
 func (s *CostTrackingService) GetTotalCost() float64 {
-	return s.data.TotalCost
+	return s.totalCost
 }
 
 // GetCostSummary returns a summary of costs
 func (s *CostTrackingService) GetCostSummary() map[string]interface{} {
-	today := time.Now().Format("2006-01-02")
+	now := time.Now()
+	today := dayRange(now)
 	todayCost := 0.0
-
-	if daily, exists := s.data.DailyTotals[today]; exists {
-		todayCost = daily.TotalCost
+	if entries, err := s.store.Query(today, today.AddDate(0, 0, 1)); err == nil {
+		for _, entry := range entries {
+			todayCost += entry.Cost
+		}
 	}
 
 	return map[string]interface{}{
-		"session_cost":        s.data.SessionCost,
-		"session_start":       s.data.SessionStart.Format("15:04:05"),
-		"today_cost":          todayCost,
-		"total_cost":          s.data.TotalCost,
-		"total_duration_min":  s.data.TotalDuration / 60.0,
-		"total_transcripts":   len(s.data.Entries),
-		"cost_per_minute":     WHISPER_COST_PER_MINUTE,
-		"last_updated":        s.data.LastUpdated.Format("15:04:05"),
+		"session_cost":       s.sessionCost,
+		"session_start":      s.sessionStart.Format("15:04:05"),
+		"today_cost":         todayCost,
+		"total_cost":         s.totalCost,
+		"total_duration_min": s.totalDuration / 60.0,
+		"total_transcripts":  s.totalCount,
+		"cost_per_minute":    WHISPER_COST_PER_MINUTE,
+		"last_updated":       now.Format("15:04:05"),
 	}
 }
 
-// GetDailyCosts returns daily cost breakdown for the last N days
+// GetDailyCosts returns daily cost breakdown for the last N days, querying
+// the store directly rather than an in-memory aggregate, so it works the
+// same way regardless of how large the underlying history has grown.
 func (s *CostTrackingService) GetDailyCosts(days int) []DailyCost {
 	var costs []DailyCost
 
-	// Get the last N days
 	for i := days - 1; i >= 0; i-- {
-		date := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
-		if daily, exists := s.data.DailyTotals[date]; exists {
-			costs = append(costs, daily)
-		} else {
-			// Add empty day
-			costs = append(costs, DailyCost{
-				Date:           date,
-				TotalCost:      0.0,
-				TotalDuration:  0.0,
-				TranscriptCount: 0,
-			})
-		}
+		day := dayRange(time.Now().AddDate(0, 0, -i))
+		costs = append(costs, s.aggregate(day, day.AddDate(0, 0, 1), day.Format("2006-01-02")))
 	}
 
 	return costs
 }
 
+// GetHourlyCosts returns hourly cost breakdown for the last N hours, the
+// finer-grained counterpart to GetDailyCosts for a dashboard that needs to
+// see today's cost ramp rather than just the daily total.
+func (s *CostTrackingService) GetHourlyCosts(hours int) []DailyCost {
+	var costs []DailyCost
+
+	now := time.Now()
+	hourStart := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	for i := hours - 1; i >= 0; i-- {
+		hour := hourStart.Add(-time.Duration(i) * time.Hour)
+		costs = append(costs, s.aggregate(hour, hour.Add(time.Hour), hour.Format("2006-01-02T15:00")))
+	}
+
+	return costs
+}
+
+// aggregate sums every entry in [from, to) into a single DailyCost labeled
+// label, shared by GetDailyCosts and GetHourlyCosts since both just differ
+// in bucket width.
+func (s *CostTrackingService) aggregate(from, to time.Time, label string) DailyCost {
+	daily := DailyCost{Date: label}
+
+	entries, err := s.store.Query(from, to)
+	if err != nil {
+		costLog.Debugf("Error querying cost store for %s: %v\n", label, err)
+		return daily
+	}
+
+	for _, entry := range entries {
+		daily.TotalCost += entry.Cost
+		daily.TotalDuration += entry.DurationSec
+		daily.TranscriptCount++
+	}
+
+	return daily
+}
+
+// dayRange truncates t to midnight in its own location.
+func dayRange(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 // EstimateCost estimates cost for a given duration without recording it
 func (s *CostTrackingService) EstimateCost(durationSeconds float64) float64 {
 	return s.CalculateCost(durationSeconds)
 }
 
-// loadData loads cost data from the JSON file
-func (s *CostTrackingService) loadData() error {
-	if _, err := os.Stat(s.dataFile); os.IsNotExist(err) {
-		// File doesn't exist, use defaults
-		return nil
-	}
+// ResetSessionCost resets the session cost counter
+func (s *CostTrackingService) ResetSessionCost() {
+	s.sessionStart = time.Now()
+	s.sessionCost = 0.0
+}
 
-	data, err := os.ReadFile(s.dataFile)
-	if err != nil {
-		fmt.Printf("Error reading cost data file: %v\n", err)
-		return err
-	}
+// Close releases the underlying CostStore's resources (e.g. the SQLite
+// backend's *sql.DB).
+func (s *CostTrackingService) Close() error {
+	return s.store.Close()
+}
 
-	err = json.Unmarshal(data, &s.data)
-	if err != nil {
-		fmt.Printf("Error parsing cost data file: %v\n", err)
-		return err
+// GetBudget returns the currently configured budget limits.
+func (s *CostTrackingService) GetBudget() BudgetConfig {
+	return s.budget
+}
+
+// SetBudget updates the budget limits and persists them via
+// saveBudgetConfig, so they survive a restart and sync to other devices
+// through Convex when available.
+func (s *CostTrackingService) SetBudget(budget BudgetConfig) error {
+	if err := saveBudgetConfig(s.convexClient, budget); err != nil {
+		return fmt.Errorf("failed to save budget config: %w", err)
 	}
 
-	fmt.Printf("Loaded cost data: Total cost $%.4f, %d entries\n", s.data.TotalCost, len(s.data.Entries))
+	s.budget = budget
 	return nil
 }
 
-// saveData saves cost data to the JSON file
-func (s *CostTrackingService) saveData() error {
-	// Ensure directory exists
-	dir := filepath.Dir(s.dataFile)
-	if dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
-		}
+// BudgetStatus is CheckBudget's verdict on whether a parser should be
+// allowed to dispatch a job given its estimated cost.
+type BudgetStatus struct {
+	Allowed bool `json:"allowed"`
+	// Warn is set when the job is allowed but would push spend past
+	// budgetWarnThreshold of the daily or monthly limit; the session and
+	// per-parser limits never set it.
+	Warn   bool   `json:"warn"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// CheckBudget reports whether a job expected to cost estimatedCost on
+// parserId would fit within the configured daily/monthly/session/per-parser
+// limits. A zero limit is treated as unlimited. It's called by
+// checkJobBudget before dispatching a job, and doesn't itself record
+// anything - RecordCost is what actually books the spend once the job
+// completes.
+func (s *CostTrackingService) CheckBudget(parserId string, estimatedCost float64) BudgetStatus {
+	if projected := s.sessionCost + estimatedCost; s.budget.SessionLimitUSD > 0 && projected > s.budget.SessionLimitUSD {
+		return BudgetStatus{Allowed: false, Reason: fmt.Sprintf("session cost $%.2f would exceed session limit $%.2f", projected, s.budget.SessionLimitUSD)}
 	}
 
-	data, err := json.MarshalIndent(s.data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal cost data: %w", err)
+	now := time.Now()
+	today := dayRange(now)
+	todayCost := s.aggregate(today, today.AddDate(0, 0, 1), "").TotalCost
+	if projected := todayCost + estimatedCost; s.budget.DailyLimitUSD > 0 && projected > s.budget.DailyLimitUSD {
+		return BudgetStatus{Allowed: false, Reason: fmt.Sprintf("daily cost $%.2f would exceed daily limit $%.2f", projected, s.budget.DailyLimitUSD)}
 	}
 
-	err = os.WriteFile(s.dataFile, data, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write cost data file: %w", err)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthCost := s.aggregate(monthStart, monthStart.AddDate(0, 1, 0), "").TotalCost
+	if projected := monthCost + estimatedCost; s.budget.MonthlyLimitUSD > 0 && projected > s.budget.MonthlyLimitUSD {
+		return BudgetStatus{Allowed: false, Reason: fmt.Sprintf("monthly cost $%.2f would exceed monthly limit $%.2f", projected, s.budget.MonthlyLimitUSD)}
 	}
 
-	return nil
+	if limit, ok := s.budget.PerParserDailyLimitUSD[parserId]; ok && limit > 0 {
+		parserCost := s.parserCostSince(parserId, today)
+		if projected := parserCost + estimatedCost; projected > limit {
+			return BudgetStatus{Allowed: false, Reason: fmt.Sprintf("%s daily cost $%.2f would exceed per-parser limit $%.2f", parserId, projected, limit)}
+		}
+	}
+
+	warn := s.budget.DailyLimitUSD > 0 && todayCost+estimatedCost > s.budget.DailyLimitUSD*budgetWarnThreshold
+	warn = warn || (s.budget.MonthlyLimitUSD > 0 && monthCost+estimatedCost > s.budget.MonthlyLimitUSD*budgetWarnThreshold)
+
+	return BudgetStatus{Allowed: true, Warn: warn}
 }
 
-// ResetSessionCost resets the session cost counter
-func (s *CostTrackingService) ResetSessionCost() {
-	s.data.SessionStart = time.Now()
-	s.data.SessionCost = 0.0
-	s.saveData()
+// parserCostSince sums parserId's recorded cost from since to now, for
+// CheckBudget's per-parser limit check.
+func (s *CostTrackingService) parserCostSince(parserId string, since time.Time) float64 {
+	entries, err := s.store.Query(since, time.Now().Add(time.Second))
+	if err != nil {
+		costLog.Debugf("Error querying cost store for parser %s: %v\n", parserId, err)
+		return 0
+	}
+
+	var total float64
+	for _, entry := range entries {
+		if entry.ParserID == parserId {
+			total += entry.Cost
+		}
+	}
+	return total
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// heartbeatInterval is how often a node reports its liveness, claimed
+// jobs, and capabilities when the active store supports DistributedJobStore.
+const heartbeatInterval = 10 * time.Second
+
+// staleWorkerMissedHeartbeats is how many consecutive missed heartbeats
+// mark a node's claimed jobs for requeue.
+const staleWorkerMissedHeartbeats = 3
+
+// staleWorkerReapInterval is how often a node sweeps for other nodes' jobs
+// to reap. Every node runs the sweep; reaping the same already-requeued
+// job twice is harmless since ReapStaleWorkers is keyed off assignedNode.
+const staleWorkerReapInterval = heartbeatInterval * 3
+
+// startDistributedMode launches the heartbeat and stale-worker-reaping
+// loops when the active store supports cooperating with other nodes.
+// It's a no-op for LocalJobStore, which has no other nodes to coordinate
+// with.
+func (j *JobQueueServiceImpl) startDistributedMode(ctx context.Context) {
+	distStore, ok := j.store.(DistributedJobStore)
+	if !ok {
+		return
+	}
+
+	go j.heartbeatLoop(ctx, distStore)
+	go j.reapStaleWorkersLoop(ctx, distStore)
+}
+
+// heartbeatLoop sends an immediate heartbeat and then one every
+// heartbeatInterval until ctx is cancelled.
+func (j *JobQueueServiceImpl) heartbeatLoop(ctx context.Context, store DistributedJobStore) {
+	j.sendHeartbeat(store)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.sendHeartbeat(store)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendHeartbeat reports this node's currently claimed jobs (the same set
+// tracked in jobsInProgress) and capabilities.
+func (j *JobQueueServiceImpl) sendHeartbeat(store DistributedJobStore) {
+	j.mutex.RLock()
+	claimed := make([]string, 0, len(j.jobsInProgress))
+	for id := range j.jobsInProgress {
+		claimed = append(claimed, id)
+	}
+	j.mutex.RUnlock()
+
+	if err := store.Heartbeat(j.nodeID, claimed, j.capabilities()); err != nil {
+		log.Printf("Warning: failed to send worker heartbeat: %v", err)
+	}
+}
+
+// capabilities describes what this node can do, advertised on every
+// heartbeat so claim/routing decisions elsewhere in the cluster can take
+// it into account (e.g. which parsers are enabled, whether a GPU slot is
+// available, which local path prefixes it can read files under).
+func (j *JobQueueServiceImpl) capabilities() map[string]interface{} {
+	caps := map[string]interface{}{}
+
+	if parsers, err := j.parserManager.GetEnabledParsers(); err == nil {
+		ids := make([]string, 0, len(parsers))
+		for _, p := range parsers {
+			ids = append(ids, p.GetID())
+		}
+		caps["parsers"] = ids
+	}
+
+	if j.resources.hasGPUCapacity() {
+		caps["gpu"] = true
+	}
+
+	if prefixes := j.currentPathPrefixes(); len(prefixes) > 0 {
+		caps["pathPrefixes"] = prefixes
+	}
+
+	return caps
+}
+
+// reapStaleWorkersLoop sweeps for jobs stuck on a node that's missed
+// staleWorkerMissedHeartbeats heartbeats every staleWorkerReapInterval
+// until ctx is cancelled.
+func (j *JobQueueServiceImpl) reapStaleWorkersLoop(ctx context.Context, store DistributedJobStore) {
+	ticker := time.NewTicker(staleWorkerReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n, err := store.ReapStaleWorkers(staleWorkerMissedHeartbeats)
+			if err != nil {
+				log.Printf("Warning: failed to reap stale workers: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Reaped %d job(s) from workers that missed %d heartbeats", n, staleWorkerMissedHeartbeats)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// claimNextJob picks up the next pending job, going through
+// DistributedJobStore.ClaimNextPending's compare-and-set when the active
+// store supports it so two nodes can't both claim the same job, and falling
+// back to the single-node weighted fair scheduler otherwise. Distributed
+// claims don't go through the fair scheduler yet - ClaimNextPending's own
+// priority/pathPrefixes ordering is unchanged - so weighted fair-share only
+// applies within a single node for now.
+func (j *JobQueueServiceImpl) claimNextJob() (*JobRecord, error) {
+	if distStore, ok := j.store.(DistributedJobStore); ok {
+		return distStore.ClaimNextPending(j.nodeID, j.currentPathPrefixes())
+	}
+	return j.claimNextJobFairly()
+}
+
+// SetLocalPathPrefixes configures which local filesystem path prefixes
+// this node can read files under, advertised via capabilities so a
+// deployment without a shared mount can route parse jobs to the node that
+// actually holds the file. It also (re)starts an fsnotify watch on those
+// paths so a file dropped under one wakes the job poller immediately
+// instead of waiting for jobQueueFallbackPollInterval.
+func (j *JobQueueServiceImpl) SetLocalPathPrefixes(prefixes []string) {
+	j.mutex.Lock()
+	j.pathPrefixes = prefixes
+	j.mutex.Unlock()
+
+	j.watchPathsForWake(prefixes)
+}
+
+// currentPathPrefixes returns the path prefixes set via SetLocalPathPrefixes.
+func (j *JobQueueServiceImpl) currentPathPrefixes() []string {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	return j.pathPrefixes
+}
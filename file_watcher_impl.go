@@ -2,18 +2,35 @@ package main
 
 import (
 	"context"
-	"crypto/md5"
 	"fmt"
-	"io"
-	"log"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// fileWatcherQuietPeriod is how long a path's Create/Write events must stop
+// arriving before handleFileEvent's debounce fires the matching
+// add/modify handler, so an editor's write-then-rename save produces one
+// Convex mutation instead of several half-baked ones.
+const fileWatcherQuietPeriod = 500 * time.Millisecond
+
+// pendingFileSync tracks a debounced Create/Write event awaiting its quiet
+// period, so a later event for the same path resets the timer instead of
+// scheduling a second flush. ctx carries the request ID minted when the
+// burst started, so every log line for the eventual flush - however many
+// events reset the timer first - is correlatable under one request_id.
+type pendingFileSync struct {
+	timer *time.Timer
+	isNew bool
+	ctx   context.Context
+}
+
 type FileWatcherServiceImpl struct {
 	convexClient    *ConvexClient
 	watcher         *fsnotify.Watcher
@@ -21,6 +38,14 @@ type FileWatcherServiceImpl struct {
 	folderId        string
 	isMonitoring    bool
 	ctx             context.Context
+
+	// watchedDirs tracks every directory fsnotify currently has a watch on,
+	// so a Remove/Rename on a directory can tear down its watches (and
+	// those of everything beneath it) via unwatchSubtree.
+	watchedDirs map[string]bool
+
+	pendingMu     sync.Mutex
+	pendingEvents map[string]*pendingFileSync
 }
 
 func NewFileWatcherServiceImpl(convexClient *ConvexClient) *FileWatcherServiceImpl {
@@ -65,19 +90,34 @@ func (f *FileWatcherServiceImpl) StartMonitoring() error {
 
 	f.watcher = watcher
 	f.isMonitoring = true
-
-	// Add the folder to watch
-	err = f.watcher.Add(f.monitoredFolder)
-	if err != nil {
+	f.pendingEvents = make(map[string]*pendingFileSync)
+
+	// fsnotify watches are not recursive on their own, so walk the tree
+	// once at startup to seed a watch on every subdirectory; handleFileEvent
+	// adds and removes watches as subdirectories are created or removed.
+	watchedDirs := make(map[string]bool)
+	walkErr := filepath.WalkDir(f.monitoredFolder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if addErr := f.watcher.Add(path); addErr != nil {
+			appLog.Warn(context.Background(), "failed to watch directory", F("folder_id", f.folderId), F("file_path", path), F("error", addErr.Error()))
+			return nil
+		}
+		watchedDirs[path] = true
+		return nil
+	})
+	if walkErr != nil {
 		f.watcher.Close()
 		f.isMonitoring = false
-		return fmt.Errorf("failed to watch folder: %v", err)
+		return fmt.Errorf("failed to watch folder: %v", walkErr)
 	}
+	f.watchedDirs = watchedDirs
 
 	// Initial scan
 	err = f.ScanFolder()
 	if err != nil {
-		log.Printf("Warning: initial folder scan failed: %v", err)
+		appLog.Warn(context.Background(), "initial folder scan failed", F("folder_id", f.folderId), F("error", err.Error()))
 	}
 
 	// Start watching for events
@@ -89,7 +129,7 @@ func (f *FileWatcherServiceImpl) StartMonitoring() error {
 		"isMonitoring": true,
 	})
 
-	log.Printf("Started monitoring folder: %s", f.monitoredFolder)
+	appLog.Info(context.Background(), "started monitoring folder", F("folder_id", f.folderId), F("file_path", f.monitoredFolder))
 	return err
 }
 
@@ -100,10 +140,18 @@ func (f *FileWatcherServiceImpl) StopMonitoring() error {
 
 	f.isMonitoring = false
 
+	f.pendingMu.Lock()
+	for _, pending := range f.pendingEvents {
+		pending.timer.Stop()
+	}
+	f.pendingEvents = nil
+	f.pendingMu.Unlock()
+
 	if f.watcher != nil {
 		f.watcher.Close()
 		f.watcher = nil
 	}
+	f.watchedDirs = nil
 
 	// Update folder monitoring status in Convex
 	if f.folderId != "" {
@@ -112,11 +160,11 @@ func (f *FileWatcherServiceImpl) StopMonitoring() error {
 			"isMonitoring": false,
 		})
 		if err != nil {
-			log.Printf("Warning: failed to update monitoring status: %v", err)
+			appLog.Warn(context.Background(), "failed to update monitoring status", F("folder_id", f.folderId), F("error", err.Error()))
 		}
 	}
 
-	log.Printf("Stopped monitoring folder: %s", f.monitoredFolder)
+	appLog.Info(context.Background(), "stopped monitoring folder", F("folder_id", f.folderId), F("file_path", f.monitoredFolder))
 	return nil
 }
 
@@ -129,7 +177,7 @@ func (f *FileWatcherServiceImpl) ScanFolder() error {
 		return fmt.Errorf("no folder selected")
 	}
 
-	log.Printf("Scanning folder: %s", f.monitoredFolder)
+	appLog.Info(context.Background(), "scanning folder", F("folder_id", f.folderId), F("file_path", f.monitoredFolder))
 
 	// Get current files from filesystem
 	currentFiles := make(map[string]FileInfo)
@@ -144,7 +192,7 @@ func (f *FileWatcherServiceImpl) ScanFolder() error {
 
 		fileInfo, err := f.createFileInfo(path, info)
 		if err != nil {
-			log.Printf("Warning: failed to process file %s: %v", path, err)
+			appLog.Warn(context.Background(), "failed to process file during scan", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 			return nil
 		}
 
@@ -177,30 +225,32 @@ func (f *FileWatcherServiceImpl) ScanFolder() error {
 
 	// Add new files and update existing ones
 	for path, fileInfo := range currentFiles {
+		scanCtx := WithRequestID(context.Background(), NewRequestID())
 		if existingFiles[path] {
 			// File exists, update it
-			err = f.updateFileInConvex(fileInfo)
+			err = f.updateFileInConvex(scanCtx, fileInfo)
 		} else {
 			// New file, add it
-			err = f.addFileToConvex(fileInfo)
+			err = f.addFileToConvex(scanCtx, fileInfo)
 		}
 
 		if err != nil {
-			log.Printf("Warning: failed to sync file %s: %v", path, err)
+			appLog.Warn(scanCtx, "failed to sync file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 		}
 	}
 
 	// Remove files that no longer exist
 	for path := range existingFiles {
 		if _, exists := currentFiles[path]; !exists {
-			err = f.removeFileFromConvex(path)
+			removeCtx := WithRequestID(context.Background(), NewRequestID())
+			err = f.removeFileFromConvex(removeCtx, path)
 			if err != nil {
-				log.Printf("Warning: failed to remove file %s: %v", path, err)
+				appLog.Warn(removeCtx, "failed to remove file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 			}
 		}
 	}
 
-	log.Printf("Folder scan completed. Found %d files", len(currentFiles))
+	appLog.Info(context.Background(), "folder scan completed", F("folder_id", f.folderId), F("file_count", len(currentFiles)))
 	return nil
 }
 
@@ -208,6 +258,13 @@ func (f *FileWatcherServiceImpl) GetSelectedFolder() string {
 	return f.monitoredFolder
 }
 
+// GetSelectedFolderID returns the Convex folder ID of the currently
+// monitored folder, used by VoiceWorkerService.DryRun to list its files
+// without needing the caller to already know the ID.
+func (f *FileWatcherServiceImpl) GetSelectedFolderID() string {
+	return f.folderId
+}
+
 func (f *FileWatcherServiceImpl) SetContext(ctx context.Context) {
 	f.ctx = ctx
 }
@@ -239,28 +296,142 @@ func (f *FileWatcherServiceImpl) watchEvents() {
 			if !ok {
 				return
 			}
-			log.Printf("Watcher error: %v", err)
+			appLog.Warn(context.Background(), "watcher error", F("folder_id", f.folderId), F("error", err.Error()))
 		}
 	}
 }
 
+// handleFileEvent dispatches a single fsnotify event. Create on a directory
+// recursively adds watches for it (and anything already inside it, in case
+// a whole tree was moved in at once) instead of treating it as a file.
+// Create/Write on a file are debounced through scheduleFileSync rather than
+// synced immediately, so a burst of Write events from one save only
+// produces one Convex mutation. Remove and Rename are handled identically:
+// fsnotify reports a Rename as the old path going away, with a Create for
+// the new path following separately once it lands inside a watched
+// directory, so treating it as a delete of the old path is sufficient.
 func (f *FileWatcherServiceImpl) handleFileEvent(event fsnotify.Event) {
 	path := event.Name
+	ctx := WithRequestID(context.Background(), NewRequestID())
 
 	switch {
 	case event.Op&fsnotify.Create == fsnotify.Create:
-		f.handleFileAdded(path)
-	case event.Op&fsnotify.Remove == fsnotify.Remove:
-		f.handleFileRemoved(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			f.watchSubtree(path)
+			return
+		}
+		f.scheduleFileSync(ctx, path, true)
 	case event.Op&fsnotify.Write == fsnotify.Write:
-		f.handleFileModified(path)
+		f.scheduleFileSync(ctx, path, false)
+	case event.Op&fsnotify.Remove == fsnotify.Remove, event.Op&fsnotify.Rename == fsnotify.Rename:
+		f.cancelFileSync(path)
+		f.unwatchSubtree(path)
+		f.handleFileRemoved(ctx, path)
+	}
+}
+
+// watchSubtree adds a watch on dir and every subdirectory beneath it,
+// recording each in f.watchedDirs. Used when handleFileEvent sees a new
+// directory created under the monitored folder after monitoring has
+// already started.
+func (f *FileWatcherServiceImpl) watchSubtree(dir string) {
+	if f.watcher == nil {
+		return
+	}
+
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if addErr := f.watcher.Add(path); addErr != nil {
+			appLog.Warn(context.Background(), "failed to watch directory", F("folder_id", f.folderId), F("file_path", path), F("error", addErr.Error()))
+			return nil
+		}
+		f.watchedDirs[path] = true
+		return nil
+	})
+	if walkErr != nil {
+		appLog.Warn(context.Background(), "failed to walk new directory", F("folder_id", f.folderId), F("file_path", dir), F("error", walkErr.Error()))
 	}
 }
 
-func (f *FileWatcherServiceImpl) handleFileAdded(path string) {
+// unwatchSubtree removes dir, and any previously-watched directories
+// beneath it, from the active watch set after a remove/rename event. A
+// no-op for an ordinary file path, since those were never added as watches.
+func (f *FileWatcherServiceImpl) unwatchSubtree(dir string) {
+	if f.watcher == nil || !f.watchedDirs[dir] {
+		return
+	}
+
+	prefix := dir + string(os.PathSeparator)
+	for watched := range f.watchedDirs {
+		if watched == dir || strings.HasPrefix(watched, prefix) {
+			f.watcher.Remove(watched)
+			delete(f.watchedDirs, watched)
+		}
+	}
+}
+
+// scheduleFileSync debounces Create/Write events for path: every call
+// resets path's quiet-period timer, so only the last event in a burst
+// flushes to Convex, fileWatcherQuietPeriod after the burst goes quiet.
+// isNew reflects the first event seen for the path - a Create/Write burst
+// that starts with a Create is flushed as an add, otherwise as a modify.
+func (f *FileWatcherServiceImpl) scheduleFileSync(ctx context.Context, path string, isNew bool) {
+	f.pendingMu.Lock()
+	defer f.pendingMu.Unlock()
+
+	if pending, ok := f.pendingEvents[path]; ok {
+		pending.timer.Stop()
+		pending.timer = time.AfterFunc(fileWatcherQuietPeriod, func() { f.flushFileSync(path) })
+		return
+	}
+
+	f.pendingEvents[path] = &pendingFileSync{
+		isNew: isNew,
+		ctx:   ctx,
+		timer: time.AfterFunc(fileWatcherQuietPeriod, func() { f.flushFileSync(path) }),
+	}
+}
+
+// cancelFileSync drops any pending debounced sync for path, e.g. because
+// the file was removed or renamed away before its quiet period elapsed.
+func (f *FileWatcherServiceImpl) cancelFileSync(path string) {
+	f.pendingMu.Lock()
+	defer f.pendingMu.Unlock()
+
+	if pending, ok := f.pendingEvents[path]; ok {
+		pending.timer.Stop()
+		delete(f.pendingEvents, path)
+	}
+}
+
+// flushFileSync fires once a path's quiet period has elapsed with no
+// further events, dispatching to handleFileAdded or handleFileModified
+// depending on which kind of event started the debounced burst.
+func (f *FileWatcherServiceImpl) flushFileSync(path string) {
+	f.pendingMu.Lock()
+	pending, ok := f.pendingEvents[path]
+	if ok {
+		delete(f.pendingEvents, path)
+	}
+	f.pendingMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if pending.isNew {
+		f.handleFileAdded(pending.ctx, path)
+	} else {
+		f.handleFileModified(pending.ctx, path)
+	}
+}
+
+func (f *FileWatcherServiceImpl) handleFileAdded(ctx context.Context, path string) {
 	info, err := os.Stat(path)
 	if err != nil {
-		log.Printf("Warning: cannot stat added file %s: %v", path, err)
+		appLog.Warn(ctx, "cannot stat added file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 		return
 	}
 
@@ -270,31 +441,31 @@ func (f *FileWatcherServiceImpl) handleFileAdded(path string) {
 
 	fileInfo, err := f.createFileInfo(path, info)
 	if err != nil {
-		log.Printf("Warning: failed to process added file %s: %v", path, err)
+		appLog.Warn(ctx, "failed to process added file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 		return
 	}
 
-	err = f.addFileToConvex(fileInfo)
+	err = f.addFileToConvex(ctx, fileInfo)
 	if err != nil {
-		log.Printf("Warning: failed to add file to database %s: %v", path, err)
+		appLog.Warn(ctx, "failed to add file to database", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 	}
 
-	log.Printf("File added: %s", path)
+	appLog.Info(ctx, "file added", F("folder_id", f.folderId), F("file_path", path))
 }
 
-func (f *FileWatcherServiceImpl) handleFileRemoved(path string) {
-	err := f.removeFileFromConvex(path)
+func (f *FileWatcherServiceImpl) handleFileRemoved(ctx context.Context, path string) {
+	err := f.removeFileFromConvex(ctx, path)
 	if err != nil {
-		log.Printf("Warning: failed to remove file from database %s: %v", path, err)
+		appLog.Warn(ctx, "failed to remove file from database", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 	}
 
-	log.Printf("File removed: %s", path)
+	appLog.Info(ctx, "file removed", F("folder_id", f.folderId), F("file_path", path))
 }
 
-func (f *FileWatcherServiceImpl) handleFileModified(path string) {
+func (f *FileWatcherServiceImpl) handleFileModified(ctx context.Context, path string) {
 	info, err := os.Stat(path)
 	if err != nil {
-		log.Printf("Warning: cannot stat modified file %s: %v", path, err)
+		appLog.Warn(ctx, "cannot stat modified file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 		return
 	}
 
@@ -304,16 +475,16 @@ func (f *FileWatcherServiceImpl) handleFileModified(path string) {
 
 	fileInfo, err := f.createFileInfo(path, info)
 	if err != nil {
-		log.Printf("Warning: failed to process modified file %s: %v", path, err)
+		appLog.Warn(ctx, "failed to process modified file", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 		return
 	}
 
-	err = f.updateFileInConvex(fileInfo)
+	err = f.updateFileInConvex(ctx, fileInfo)
 	if err != nil {
-		log.Printf("Warning: failed to update file in database %s: %v", path, err)
+		appLog.Warn(ctx, "failed to update file in database", F("folder_id", f.folderId), F("file_path", path), F("error", err.Error()))
 	}
 
-	log.Printf("File modified: %s", path)
+	appLog.Info(ctx, "file modified", F("folder_id", f.folderId), F("file_path", path))
 }
 
 func (f *FileWatcherServiceImpl) createFileInfo(path string, info os.FileInfo) (FileInfo, error) {
@@ -326,6 +497,8 @@ func (f *FileWatcherServiceImpl) createFileInfo(path string, info os.FileInfo) (
 	// Format file size
 	sizeStr := fmt.Sprintf("%.2f MB", float64(info.Size())/(1024*1024))
 
+	filesDiscoveredTotal.WithLabelValues(strings.ToLower(filepath.Ext(path))).Inc()
+
 	return FileInfo{
 		Path:      path,
 		Name:      info.Name(),
@@ -357,22 +530,6 @@ func (f *FileWatcherServiceImpl) determineFileType(path string) string {
 	return "other"
 }
 
-func (f *FileWatcherServiceImpl) calculateFileHash(path string) (string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hasher := md5.New()
-	_, err = io.Copy(hasher, file)
-	if err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
-}
-
 func (f *FileWatcherServiceImpl) isOutputFile(path string) bool {
 	name := filepath.Base(path)
 
@@ -399,14 +556,15 @@ func (f *FileWatcherServiceImpl) isOutputFile(path string) bool {
 	return false
 }
 
-func (f *FileWatcherServiceImpl) addFileToConvex(fileInfo FileInfo) error {
+func (f *FileWatcherServiceImpl) addFileToConvex(ctx context.Context, fileInfo FileInfo) error {
 	// Calculate hash for the file
-	hash, err := f.calculateFileHash(fileInfo.Path)
+	hash, err := calculateFileHash(ctx, f.convexClient, fileInfo.Path)
 	if err != nil {
-		log.Printf("Warning: failed to calculate hash for %s: %v", fileInfo.Path, err)
+		appLog.Warn(ctx, "failed to calculate hash", F("folder_id", f.folderId), F("file_path", fileInfo.Path), F("error", err.Error()))
 		hash = ""
 	}
 
+	appLog.Info(ctx, "creating file in convex", F("folder_id", f.folderId), F("file_path", fileInfo.Path), F("hash", hash))
 	_, err = f.convexClient.CallMutation("files:create", map[string]interface{}{
 		"path":      fileInfo.Path,
 		"name":      fileInfo.Name,
@@ -422,14 +580,15 @@ func (f *FileWatcherServiceImpl) addFileToConvex(fileInfo FileInfo) error {
 	return err
 }
 
-func (f *FileWatcherServiceImpl) updateFileInConvex(fileInfo FileInfo) error {
+func (f *FileWatcherServiceImpl) updateFileInConvex(ctx context.Context, fileInfo FileInfo) error {
 	// Calculate hash for the file
-	hash, err := f.calculateFileHash(fileInfo.Path)
+	hash, err := calculateFileHash(ctx, f.convexClient, fileInfo.Path)
 	if err != nil {
-		log.Printf("Warning: failed to calculate hash for %s: %v", fileInfo.Path, err)
+		appLog.Warn(ctx, "failed to calculate hash", F("folder_id", f.folderId), F("file_path", fileInfo.Path), F("error", err.Error()))
 		hash = ""
 	}
 
+	appLog.Info(ctx, "updating file in convex", F("folder_id", f.folderId), F("file_path", fileInfo.Path), F("hash", hash))
 	_, err = f.convexClient.CallMutation("files:updateByPath", map[string]interface{}{
 		"path":      fileInfo.Path,
 		"sizeBytes": fileInfo.SizeBytes,
@@ -440,7 +599,8 @@ func (f *FileWatcherServiceImpl) updateFileInConvex(fileInfo FileInfo) error {
 	return err
 }
 
-func (f *FileWatcherServiceImpl) removeFileFromConvex(path string) error {
+func (f *FileWatcherServiceImpl) removeFileFromConvex(ctx context.Context, path string) error {
+	appLog.Info(ctx, "removing file from convex", F("folder_id", f.folderId), F("file_path", path))
 	_, err := f.convexClient.CallMutation("files:deleteByPath", map[string]interface{}{
 		"path": path,
 	})
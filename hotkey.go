@@ -0,0 +1,397 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/rakyll/portmidi"
+	"golang.design/x/hotkey"
+)
+
+var hotkeyLog = GetFacility("hotkey")
+
+const hotkeyBindingsFile = "hotkey_bindings.json"
+
+// Recording actions a trigger (hotkey or MIDI note) can be bound to.
+const (
+	HotkeyActionStart  = "start"
+	HotkeyActionStop   = "stop"
+	HotkeyActionToggle = "toggle"
+)
+
+// GlobalHotkeyBinding maps an OS-global key combination, expressed the way
+// Electron/Wails accelerators are ("CmdOrCtrl+Shift+R"), to a recording action.
+type GlobalHotkeyBinding struct {
+	Accelerator string `json:"accelerator"`
+	Action      string `json:"action"`
+}
+
+// MidiTriggerBinding maps a MIDI note-on from a named input device (e.g. a
+// USB foot pedal) to a recording action.
+type MidiTriggerBinding struct {
+	DeviceName string `json:"deviceName"`
+	Note       int    `json:"note"`
+	Action     string `json:"action"`
+}
+
+// hotkeyBindingConfig is the persisted shape of every binding the user has
+// configured, across both trigger types.
+type hotkeyBindingConfig struct {
+	GlobalHotkeys []GlobalHotkeyBinding `json:"globalHotkeys"`
+	MidiTriggers  []MidiTriggerBinding  `json:"midiTriggers"`
+}
+
+// HotkeyService lets dictation users drive recording hands-free, binding
+// Start/Stop/Toggle to either a global OS hotkey (golang.design/x/hotkey)
+// or a MIDI note-on from a pedal/pad controller (rakyll/portmidi), mirroring
+// the enumeration pattern AudioService/PlaybackService use for PortAudio
+// devices. It calls straight into App.StartRecording/StopRecording.
+type HotkeyService struct {
+	app *App
+
+	mutex    sync.Mutex
+	bindings hotkeyBindingConfig
+
+	registeredHotkeys []*hotkey.Hotkey
+	midiStream        *portmidi.Stream
+	midiDone          chan struct{}
+}
+
+// NewHotkeyService creates a new HotkeyService and loads any previously
+// persisted bindings. It does not register them yet; call Start for that
+// once the app context is ready.
+func NewHotkeyService(app *App) *HotkeyService {
+	h := &HotkeyService{app: app}
+	h.loadBindings()
+	return h
+}
+
+// Start registers every persisted binding. Call once during startup, after
+// PortAudio/portmidi have been initialized elsewhere.
+func (h *HotkeyService) Start() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, binding := range h.bindings.GlobalHotkeys {
+		if err := h.registerGlobalHotkey(binding); err != nil {
+			hotkeyLog.Debugf("Error registering hotkey %q: %v\n", binding.Accelerator, err)
+		}
+	}
+	if len(h.bindings.MidiTriggers) > 0 {
+		if err := h.startMidiListener(); err != nil {
+			hotkeyLog.Debugf("Error starting MIDI listener: %v\n", err)
+		}
+	}
+}
+
+// Stop unregisters every hotkey and closes the MIDI stream, if open.
+func (h *HotkeyService) Stop() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, hk := range h.registeredHotkeys {
+		hk.Unregister()
+	}
+	h.registeredHotkeys = nil
+
+	if h.midiDone != nil {
+		close(h.midiDone)
+		h.midiDone = nil
+	}
+	if h.midiStream != nil {
+		h.midiStream.Close()
+		h.midiStream = nil
+	}
+}
+
+// ListMidiInputs returns the names of available MIDI input devices.
+func (h *HotkeyService) ListMidiInputs() ([]string, error) {
+	if err := portmidi.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize portmidi: %w", err)
+	}
+
+	var names []string
+	for id := 0; id < portmidi.CountDevices(); id++ {
+		info := portmidi.Info(portmidi.DeviceID(id))
+		if info != nil && info.IsInputAvailable {
+			names = append(names, info.Name)
+		}
+	}
+	return names, nil
+}
+
+// BindMidiTrigger binds a note-on from deviceName/note to action, persists
+// the binding, and (re)starts the MIDI listener so it takes effect
+// immediately.
+func (h *HotkeyService) BindMidiTrigger(deviceName string, note int, action string) error {
+	if err := validateHotkeyAction(action); err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	h.bindings.MidiTriggers = append(h.bindings.MidiTriggers, MidiTriggerBinding{
+		DeviceName: deviceName,
+		Note:       note,
+		Action:     action,
+	})
+	err := h.saveBindingsLocked()
+	h.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.startMidiListener()
+}
+
+// BindGlobalHotkey binds an OS-global accelerator (e.g. "CmdOrCtrl+Shift+R")
+// to action, persists the binding, and registers it immediately.
+func (h *HotkeyService) BindGlobalHotkey(accelerator string, action string) error {
+	if err := validateHotkeyAction(action); err != nil {
+		return err
+	}
+
+	binding := GlobalHotkeyBinding{Accelerator: accelerator, Action: action}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err := h.registerGlobalHotkey(binding); err != nil {
+		return err
+	}
+
+	h.bindings.GlobalHotkeys = append(h.bindings.GlobalHotkeys, binding)
+	return h.saveBindingsLocked()
+}
+
+// registerGlobalHotkey parses and registers a single accelerator. Caller
+// must hold h.mutex.
+func (h *HotkeyService) registerGlobalHotkey(binding GlobalHotkeyBinding) error {
+	mods, key, err := parseAccelerator(binding.Accelerator)
+	if err != nil {
+		return err
+	}
+
+	hk := hotkey.New(mods, key)
+	if err := hk.Register(); err != nil {
+		return fmt.Errorf("failed to register hotkey %q: %w", binding.Accelerator, err)
+	}
+
+	go func(action string) {
+		for range hk.Keydown() {
+			h.dispatch(action)
+		}
+	}(binding.Action)
+
+	h.registeredHotkeys = append(h.registeredHotkeys, hk)
+	return nil
+}
+
+// startMidiListener (re)opens the MIDI input device used by the first
+// configured binding and starts reading note-on events from it. Caller
+// must hold h.mutex.
+func (h *HotkeyService) startMidiListener() error {
+	if h.midiDone != nil {
+		close(h.midiDone)
+		h.midiDone = nil
+	}
+	if h.midiStream != nil {
+		h.midiStream.Close()
+		h.midiStream = nil
+	}
+	if len(h.bindings.MidiTriggers) == 0 {
+		return nil
+	}
+
+	if err := portmidi.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize portmidi: %w", err)
+	}
+
+	deviceName := h.bindings.MidiTriggers[0].DeviceName
+	deviceID := portmidi.DeviceID(-1)
+	for id := 0; id < portmidi.CountDevices(); id++ {
+		info := portmidi.Info(portmidi.DeviceID(id))
+		if info != nil && info.IsInputAvailable && info.Name == deviceName {
+			deviceID = portmidi.DeviceID(id)
+			break
+		}
+	}
+	if deviceID == -1 {
+		return fmt.Errorf("MIDI input device not found: %s", deviceName)
+	}
+
+	stream, err := portmidi.NewInputStream(deviceID, 1024)
+	if err != nil {
+		return fmt.Errorf("failed to open MIDI input %s: %w", deviceName, err)
+	}
+
+	h.midiStream = stream
+	h.midiDone = make(chan struct{})
+	go h.listenMidi(stream, h.midiDone)
+	return nil
+}
+
+// listenMidi reads note-on events from stream and dispatches the bound
+// action for any note that matches a configured MidiTriggerBinding. Exits
+// when done is closed.
+func (h *HotkeyService) listenMidi(stream *portmidi.Stream, done <-chan struct{}) {
+	const noteOnStatus = 0x90
+
+	events := stream.Listen()
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-events:
+			if event.Status&0xF0 != noteOnStatus || event.Data2 == 0 {
+				continue // ignore note-off and zero-velocity note-on
+			}
+
+			h.mutex.Lock()
+			var action string
+			for _, binding := range h.bindings.MidiTriggers {
+				if int(event.Data1) == binding.Note {
+					action = binding.Action
+					break
+				}
+			}
+			h.mutex.Unlock()
+
+			if action != "" {
+				h.dispatch(action)
+			}
+		}
+	}
+}
+
+// dispatch runs the given recording action against the app.
+func (h *HotkeyService) dispatch(action string) {
+	var err error
+	switch action {
+	case HotkeyActionStart:
+		deviceName, dErr := h.app.GetDefaultInputDevice()
+		if dErr != nil {
+			err = dErr
+			break
+		}
+		err = h.app.StartRecording(deviceName)
+	case HotkeyActionStop:
+		err = h.app.StopRecording()
+	case HotkeyActionToggle:
+		if h.app.IsRecording() {
+			err = h.app.StopRecording()
+		} else {
+			var deviceName string
+			deviceName, err = h.app.GetDefaultInputDevice()
+			if err == nil {
+				err = h.app.StartRecording(deviceName)
+			}
+		}
+	}
+
+	if err != nil {
+		hotkeyLog.Debugf("Error dispatching hotkey action %q: %v\n", action, err)
+	}
+}
+
+// validateHotkeyAction rejects anything but the three known actions.
+func validateHotkeyAction(action string) error {
+	switch action {
+	case HotkeyActionStart, HotkeyActionStop, HotkeyActionToggle:
+		return nil
+	default:
+		return fmt.Errorf("unknown hotkey action: %s (must be start, stop, or toggle)", action)
+	}
+}
+
+// parseAccelerator parses an Electron/Wails-style accelerator string (e.g.
+// "CmdOrCtrl+Shift+R") into golang.design/x/hotkey modifiers and a key.
+func parseAccelerator(accelerator string) ([]hotkey.Modifier, hotkey.Key, error) {
+	parts := strings.Split(accelerator, "+")
+	if len(parts) == 0 {
+		return nil, 0, fmt.Errorf("empty accelerator")
+	}
+
+	var mods []hotkey.Modifier
+	keyToken := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+
+	for _, part := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "cmdorctrl", "commandorcontrol", "ctrl", "control":
+			mods = append(mods, hotkey.ModCtrl)
+		case "cmd", "command":
+			mods = append(mods, hotkey.ModCmd)
+		case "alt", "option":
+			mods = append(mods, hotkey.ModOption)
+		case "shift":
+			mods = append(mods, hotkey.ModShift)
+		default:
+			return nil, 0, fmt.Errorf("unknown modifier: %s", part)
+		}
+	}
+
+	key, ok := acceleratorKeys[keyToken]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown key: %s", keyToken)
+	}
+
+	return mods, key, nil
+}
+
+// acceleratorKeys covers the letters A-Z plus Space, Return, and Escape;
+// digits and function keys are not included, so parseAccelerator rejects
+// them as unknown keys.
+var acceleratorKeys = func() map[string]hotkey.Key {
+	keys := map[string]hotkey.Key{
+		"SPACE":  hotkey.KeySpace,
+		"RETURN": hotkey.KeyReturn,
+		"ESCAPE": hotkey.KeyEscape,
+	}
+	letters := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	letterKeys := []hotkey.Key{
+		hotkey.KeyA, hotkey.KeyB, hotkey.KeyC, hotkey.KeyD, hotkey.KeyE, hotkey.KeyF, hotkey.KeyG,
+		hotkey.KeyH, hotkey.KeyI, hotkey.KeyJ, hotkey.KeyK, hotkey.KeyL, hotkey.KeyM, hotkey.KeyN,
+		hotkey.KeyO, hotkey.KeyP, hotkey.KeyQ, hotkey.KeyR, hotkey.KeyS, hotkey.KeyT, hotkey.KeyU,
+		hotkey.KeyV, hotkey.KeyW, hotkey.KeyX, hotkey.KeyY, hotkey.KeyZ,
+	}
+	for i, letter := range letters {
+		keys[string(letter)] = letterKeys[i]
+	}
+	return keys
+}()
+
+// loadBindings reads persisted bindings, leaving an empty config if none
+// have been saved yet.
+func (h *HotkeyService) loadBindings() {
+	data, err := os.ReadFile(hotkeyBindingsFile)
+	if err != nil {
+		return
+	}
+
+	var cfg hotkeyBindingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		hotkeyLog.Debugf("Error parsing hotkey bindings file: %v\n", err)
+		return
+	}
+
+	h.bindings = cfg
+}
+
+// saveBindingsLocked writes the current bindings to disk. Caller must hold
+// h.mutex.
+func (h *HotkeyService) saveBindingsLocked() error {
+	data, err := json.MarshalIndent(h.bindings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal hotkey bindings: %w", err)
+	}
+
+	if err := os.WriteFile(hotkeyBindingsFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write hotkey bindings file: %w", err)
+	}
+
+	return nil
+}
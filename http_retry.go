@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// httpxDoWithRetry runs attempt up to policy.MaxAttempts times, backing off
+// between tries per RetryPolicy.delayForAttempt and refusing to attempt at
+// all once endpoint's CircuitBreaker has tripped open. attempt should
+// perform exactly one request and report its outcome as (statusCode, body,
+// err): statusCode 0 means the request never got a response (transport
+// error), while a non-zero statusCode reports what the server returned.
+// Only retryableStatusCodes (and transport errors) are retried - a 4xx
+// other than 429 is treated as permanent and returned immediately.
+func httpxDoWithRetry(ctx context.Context, endpoint string, policy RetryPolicy, attempt func() (statusCode int, body []byte, err error)) ([]byte, error) {
+	breaker := globalCircuitBreakers.breakerFor(endpoint)
+
+	var (
+		lastBody []byte
+		lastErr  error
+	)
+
+	for attemptNum := 1; attemptNum <= policy.MaxAttempts; attemptNum++ {
+		if !breaker.Allow() {
+			return nil, fmt.Errorf("circuit breaker open for %s: too many recent failures", endpoint)
+		}
+
+		statusCode, body, err := attempt()
+		success := err == nil && statusCode < 400
+		breaker.RecordResult(success)
+
+		if success {
+			return body, nil
+		}
+
+		lastBody, lastErr = body, err
+		if lastErr == nil {
+			lastErr = fmt.Errorf("request to %s failed with status %d", endpoint, statusCode)
+		}
+
+		retryable := statusCode == 0 || retryableStatusCodes[statusCode]
+		if !retryable || attemptNum == policy.MaxAttempts {
+			break
+		}
+
+		httpRetryAttemptsTotal.WithLabelValues(endpoint).Inc()
+
+		select {
+		case <-ctx.Done():
+			return lastBody, ctx.Err()
+		case <-time.After(policy.delayForAttempt(attemptNum)):
+		}
+	}
+
+	return lastBody, lastErr
+}
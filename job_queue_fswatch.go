@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchPathsForWake watches paths (non-recursively, one fsnotify watch per
+// path) and wakes processJobQueue on every event underneath them, so a file
+// dropped into a monitored folder reaches a worker well under a second
+// instead of waiting out jobQueueFallbackPollInterval. It replaces any
+// previously-watched set, since SetLocalPathPrefixes calls this every time
+// the selected folder changes.
+func (j *JobQueueServiceImpl) watchPathsForWake(paths []string) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Warning: failed to create fsnotify watcher for job wake-up, falling back to %s polling: %v", jobQueueFallbackPollInterval, err)
+		return
+	}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if addErr := watcher.Add(path); addErr != nil {
+			log.Printf("Warning: failed to watch %s for job wake-up: %v", path, addErr)
+		}
+	}
+
+	j.mutex.Lock()
+	previous := j.fsWatcher
+	j.fsWatcher = watcher
+	j.mutex.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+
+	go j.drainWakeEvents(watcher)
+}
+
+// drainWakeEvents wakes processJobQueue on every event or error watcher
+// reports, until it's closed (by a later watchPathsForWake call replacing
+// it). It doesn't need to inspect events - any filesystem activity under a
+// watched path is worth an extra poll, and claimNextJob is cheap to call
+// with nothing pending.
+func (j *JobQueueServiceImpl) drainWakeEvents(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			j.wakeNow()
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			j.wakeNow()
+		}
+	}
+}
@@ -2,34 +2,114 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
+// jobGracefulTimeout is how long StopProcessing waits for in-flight jobs to
+// finish on their own before force-cancelling them, mirroring a two-phase
+// "soft stop, then force stop" shutdown.
+const jobGracefulTimeout = 25 * time.Second
+
+// jobQueueFallbackPollInterval is processJobQueue's safety-net poll rate
+// for work that arrives without going through wakeNow - e.g. a job another
+// node created directly against a shared store. Local work wakes the poller
+// immediately instead of waiting on this.
+const jobQueueFallbackPollInterval = 60 * time.Second
+
+// runningJob tracks an in-flight job's own cancellable context, so
+// CancelJob/PauseJob can interrupt its parser.Process call directly instead
+// of only flipping a status flag it has no way to observe.
+type runningJob struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startedAt time.Time
+}
+
 type JobQueueServiceImpl struct {
-	convexClient    *ConvexClient
-	parserManager   ParserManagerService
-	isProcessing    bool
-	stopChannel     chan bool
-	jobsInProgress  map[string]bool
-	mutex           sync.RWMutex
-	ctx             context.Context
+	convexClient   *ConvexClient
+	parserManager  ParserManagerService
+	costTracking   *CostTrackingService
+	store          JobStore
+	isProcessing   bool
+	cancel         context.CancelFunc
+	jobsInProgress map[string]*runningJob
+	mutex          sync.RWMutex
+	dispatch       chan *JobRecord
+	parserSem      *parserSemaphores
+	resources      *resourceBudget
+	shutdownDone   chan struct{}
+	outbox         *statusOutbox
+	nodeID         string
+	pathPrefixes   []string
+	wake           chan struct{}
+	fsWatcher      *fsnotify.Watcher
+	scheduler      *fairScheduler
+	ctx            context.Context
 }
 
-func NewJobQueueServiceImpl(convexClient *ConvexClient, parserManager ParserManagerService) *JobQueueServiceImpl {
+// NewJobQueueServiceImpl wires up a JobQueueServiceImpl backed by the
+// JobStore selected in the persisted JobStoreConfig, falling back to
+// ConvexJobStore if that selection can't be opened (e.g. a local db path
+// that isn't writable). convexClient is still kept directly for calls the
+// job store doesn't cover, like resolving parser-unaware file lookups.
+// costTracking gates dispatch against its configured budget via
+// checkJobBudget and may be nil, in which case budgets are unenforced.
+func NewJobQueueServiceImpl(convexClient *ConvexClient, parserManager ParserManagerService, costTracking *CostTrackingService) *JobQueueServiceImpl {
+	store, err := NewJobStore(convexClient)
+	if err != nil {
+		log.Printf("Warning: failed to initialize job store, falling back to Convex: %v", err)
+		store = NewConvexJobStore(convexClient)
+	}
+
+	outbox, err := newStatusOutbox(defaultStatusOutboxPath)
+	if err != nil {
+		log.Printf("Warning: failed to open status outbox, terminal updates won't survive a crash: %v", err)
+	}
+
+	nodeID, err := loadOrCreateNodeID()
+	if err != nil {
+		log.Printf("Warning: failed to load/create node id, falling back to a transient one: %v", err)
+		nodeID = fmt.Sprintf("node-%d", time.Now().UnixNano())
+	}
+
 	return &JobQueueServiceImpl{
 		convexClient:   convexClient,
 		parserManager:  parserManager,
+		costTracking:   costTracking,
+		store:          store,
 		isProcessing:   false,
-		stopChannel:    make(chan bool, 1),
-		jobsInProgress: make(map[string]bool),
+		jobsInProgress: make(map[string]*runningJob),
+		dispatch:       make(chan *JobRecord, defaultDispatchBuffer),
+		parserSem:      newParserSemaphores(),
+		resources:      newResourceBudget(),
+		outbox:         outbox,
+		nodeID:         nodeID,
+		wake:           make(chan struct{}, 1),
+		scheduler:      newFairScheduler(),
 	}
 }
 
+// SetContext records ctx so checkJobBudget can emit budget warning/blocked
+// events to the frontend via Wails, the same way FolderMonitorService and
+// FileWatcherServiceImpl pick up their context.
+func (j *JobQueueServiceImpl) SetContext(ctx context.Context) {
+	j.ctx = ctx
+}
+
+// StartProcessing starts defaultWorkerCount workers draining the dispatch
+// channel plus a single poller feeding it from claimNextJob, replacing
+// the old one-job-per-5-seconds ticker with a bounded concurrent pool.
 func (j *JobQueueServiceImpl) StartProcessing() {
 	j.mutex.Lock()
 	defer j.mutex.Unlock()
@@ -39,243 +119,524 @@ func (j *JobQueueServiceImpl) StartProcessing() {
 	}
 
 	j.isProcessing = true
-	j.ctx = context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.shutdownDone = make(chan struct{})
 
-	go j.processJobQueue()
+	if j.outbox != nil {
+		j.outbox.replay(j.store)
+		go j.outbox.run(ctx, j.store)
+	}
+	go j.pipelineAdvancer(ctx)
+	go j.retryScheduler(ctx)
+	go j.leaseReaperLoop(ctx)
+	go j.budgetGateSweeper(ctx)
+	j.startDistributedMode(ctx)
+
+	for i := 0; i < defaultWorkerCount; i++ {
+		go j.worker(ctx)
+	}
+	go j.processJobQueue(ctx)
 	log.Println("Job queue processing started")
 }
 
+// StopProcessing is a soft stop: it halts the poller and stops workers from
+// picking up new dispatch sends, but lets already-running jobs finish on
+// their own. A background goroutine then waits up to jobGracefulTimeout for
+// that to happen before force-cancelling whatever's left; WaitForShutdown
+// blocks until that goroutine is done either way.
 func (j *JobQueueServiceImpl) StopProcessing() {
 	j.mutex.Lock()
-	defer j.mutex.Unlock()
 
 	if !j.isProcessing {
+		j.mutex.Unlock()
 		return
 	}
 
 	j.isProcessing = false
-	select {
-	case j.stopChannel <- true:
-	default:
+	if j.cancel != nil {
+		j.cancel()
 	}
+	done := j.shutdownDone
+
+	j.mutex.Unlock()
 
 	log.Println("Job queue processing stopped")
+
+	go j.awaitShutdown(done)
+}
+
+// awaitShutdown polls for every in-flight job to finish on its own, then
+// force-cancels any stragglers once jobGracefulTimeout elapses, before
+// closing done so WaitForShutdown can return.
+func (j *JobQueueServiceImpl) awaitShutdown(done chan struct{}) {
+	defer close(done)
+
+	deadline := time.Now().Add(jobGracefulTimeout)
+	for time.Now().Before(deadline) {
+		j.mutex.RLock()
+		remaining := len(j.jobsInProgress)
+		j.mutex.RUnlock()
+
+		if remaining == 0 {
+			return
+		}
+		time.Sleep(resourceWaitInterval)
+	}
+
+	j.forceStopRemaining()
+}
+
+// forceStopRemaining marks every job still running as "interrupted" with a
+// resumable checkpoint, then cancels its context so parser.Process returns.
+// It runs once the graceful timeout has passed, so a slow or stuck parser
+// can't block shutdown indefinitely.
+func (j *JobQueueServiceImpl) forceStopRemaining() {
+	j.mutex.RLock()
+	remaining := make(map[string]*runningJob, len(j.jobsInProgress))
+	for id, rj := range j.jobsInProgress {
+		remaining[id] = rj
+	}
+	j.mutex.RUnlock()
+
+	for id, rj := range remaining {
+		if err := j.store.UpdateMetadata(id, map[string]interface{}{
+			"checkpoint": map[string]interface{}{
+				"resumable":        true,
+				"interruptedAfter": time.Since(rj.startedAt).Milliseconds(),
+			},
+		}); err != nil {
+			log.Printf("Warning: failed to record checkpoint for job %s: %v", id, err)
+		}
+		j.terminalStatus(id, "interrupted", map[string]interface{}{
+			"interruptedAt": time.Now().Unix() * 1000,
+		})
+		rj.cancel()
+	}
+}
+
+// WaitForShutdown blocks until a prior StopProcessing call has finished
+// draining (or force-stopping) every in-flight job. It's a no-op if
+// processing was never started.
+func (j *JobQueueServiceImpl) WaitForShutdown() {
+	j.mutex.RLock()
+	done := j.shutdownDone
+	j.mutex.RUnlock()
+
+	if done != nil {
+		<-done
+	}
 }
 
+// jobTimeoutMetadataKey is where QueueJob stashes JobRequest.TimeoutSec in
+// job.Metadata, since JobStore.Create only persists the fields every
+// backend already has a column for. processJobQueue reads it back off the
+// claimed JobRecord to derive that job's per-job deadline.
+const jobTimeoutMetadataKey = "timeoutSec"
+
 func (j *JobQueueServiceImpl) QueueJob(job JobRequest) error {
-	// Create job in Convex
-	_, err := j.convexClient.CallMutation("jobs:create", map[string]interface{}{
-		"fileId":   job.FileID,
-		"parserId": job.ParserID,
-		"jobType":  job.JobType,
-		"status":   "pending",
-		"priority": job.Priority,
-		"metadata": job.Metadata,
-	})
+	// If Convex job creation has been failing enough to trip its breaker,
+	// don't burn another attempt on a call we already expect to fail -
+	// surface that to the caller instead so it can decide whether to drop
+	// or re-surface the file later (ProcessAllFolderFiles logs and moves on).
+	// LocalJobStore never touches this endpoint, so its breaker never
+	// trips and this is a no-op when running in offline mode.
+	if globalCircuitBreakers.breakerFor("convex:"+jobsCreateConvexFunction).State() == circuitOpen {
+		return fmt.Errorf("not queuing job for file %s: convex job creation is currently circuit-broken", job.FileID)
+	}
 
+	if job.TimeoutSec > 0 {
+		if job.Metadata == nil {
+			job.Metadata = map[string]interface{}{}
+		}
+		job.Metadata[jobTimeoutMetadataKey] = job.TimeoutSec
+	}
+
+	_, err := j.store.Create(job)
+	if err == nil {
+		j.wakeNow()
+	}
 	return err
 }
 
+// wakeNow nudges processJobQueue to poll immediately instead of waiting out
+// the fallback ticker. It's non-blocking: if a wake is already pending the
+// send is dropped, since processJobQueue only ever needs to know "something
+// changed", not how many times.
+func (j *JobQueueServiceImpl) wakeNow() {
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+}
+
 func (j *JobQueueServiceImpl) GetQueueStatus() QueueStatus {
-	result, err := j.convexClient.CallQuery("jobs:getQueueStats", map[string]interface{}{})
+	status, err := j.store.GetQueueStats()
 	if err != nil {
 		log.Printf("Warning: failed to get queue stats: %v", err)
 		return QueueStatus{}
 	}
 
-	status := QueueStatus{}
-	if statsMap, ok := result.(map[string]interface{}); ok {
-		if total, ok := statsMap["total"].(float64); ok {
-			status.TotalJobs = int(total)
-		}
-		if pending, ok := statsMap["pending"].(float64); ok {
-			status.PendingJobs = int(pending)
-		}
-		if processing, ok := statsMap["processing"].(float64); ok {
-			status.ProcessingJobs = int(processing)
-		}
-		if completed, ok := statsMap["completed"].(float64); ok {
-			status.CompletedJobs = int(completed)
-		}
-		if failed, ok := statsMap["failed"].(float64); ok {
-			status.FailedJobs = int(failed)
-		}
-		if paused, ok := statsMap["paused"].(float64); ok {
-			status.PausedJobs = int(paused)
+	status.ResourceCPUInUse, status.ResourceMemInUseMB, status.ResourceGPUInUse = j.resources.snapshot()
+
+	j.mutex.RLock()
+	status.ActiveWorkers = len(j.jobsInProgress)
+	j.mutex.RUnlock()
+
+	if perParser, err := j.store.GetQueueStatsByParser(); err != nil {
+		log.Printf("Warning: failed to get per-parser queue stats: %v", err)
+	} else {
+		status.PerParser = perParser
+	}
+
+	if distStore, ok := j.store.(DistributedJobStore); ok {
+		peers, err := distStore.ListPeers()
+		if err != nil {
+			log.Printf("Warning: failed to list worker peers: %v", err)
+		} else {
+			status.Peers = peers
 		}
 	}
 
+	jobsByState.WithLabelValues("pending").Set(float64(status.PendingJobs))
+	jobsByState.WithLabelValues("processing").Set(float64(status.ProcessingJobs))
+	jobsByState.WithLabelValues("completed").Set(float64(status.CompletedJobs))
+	jobsByState.WithLabelValues("failed").Set(float64(status.FailedJobs))
+	jobsByState.WithLabelValues("paused").Set(float64(status.PausedJobs))
+	jobsByState.WithLabelValues("retry_scheduled").Set(float64(status.RetryScheduledJobs))
+	jobsByState.WithLabelValues("dead_letter").Set(float64(status.DeadLetterJobs))
+	jobsByState.WithLabelValues("blocked_by_budget").Set(float64(status.BlockedByBudgetJobs))
+
 	return status
 }
 
+// PauseJob persists jobId's status as "paused" in the job store, so it
+// survives an app restart the same way any other status does - nothing
+// here or in StartProcessing ever moves a paused job back to pending on its
+// own, only an explicit ResumeJob/RetryJob call does.
 func (j *JobQueueServiceImpl) PauseJob(jobId string) error {
-	_, err := j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-		"id":     jobId,
-		"status": "paused",
-	})
-	return err
+	if err := j.store.UpdateStatus(jobId, "paused", nil); err != nil {
+		return err
+	}
+	j.cancelIfRunning(jobId)
+	return nil
 }
 
 func (j *JobQueueServiceImpl) ResumeJob(jobId string) error {
-	_, err := j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-		"id":     jobId,
-		"status": "pending",
-	})
-	return err
+	return j.store.UpdateStatus(jobId, "pending", nil)
 }
 
 func (j *JobQueueServiceImpl) CancelJob(jobId string) error {
-	_, err := j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-		"id":     jobId,
-		"status": "cancelled",
-	})
-	return err
+	if err := j.store.UpdateStatus(jobId, "cancelled", nil); err != nil {
+		return err
+	}
+	j.cancelIfRunning(jobId)
+	return nil
+}
+
+// terminalStatus routes a completed/failed/interrupted update through the
+// outbox so it's durably retried until the store acknowledges it, rather
+// than risking a network blip leaving the job stuck in "processing"
+// forever. Falls back to a direct (best-effort) write if the outbox failed
+// to open.
+func (j *JobQueueServiceImpl) terminalStatus(jobId, status string, fields map[string]interface{}) {
+	if j.outbox == nil {
+		if err := j.store.UpdateStatus(jobId, status, fields); err != nil {
+			log.Printf("Error updating job %s to %s: %v", jobId, status, err)
+		}
+		return
+	}
+	j.outbox.enqueue(j.store, jobId, status, fields)
+}
+
+// cancelIfRunning cancels jobId's own context if it's currently being
+// processed, so a pause/cancel request interrupts an in-flight
+// parser.Process call instead of only taking effect on the next job.
+func (j *JobQueueServiceImpl) cancelIfRunning(jobId string) {
+	j.mutex.RLock()
+	rj, ok := j.jobsInProgress[jobId]
+	j.mutex.RUnlock()
+
+	if ok {
+		rj.cancel()
+	}
 }
 
 func (j *JobQueueServiceImpl) RetryJob(jobId string) error {
-	_, err := j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-		"id":     jobId,
-		"status": "pending",
-	})
-	return err
+	return j.store.UpdateStatus(jobId, "pending", nil)
+}
+
+// GetJob returns jobId's full detail, decoding the retry bookkeeping
+// handleJobFailure stashed in its metadata - attempt history and the next
+// scheduled retry time - that JobRecord's own fields don't carry.
+func (j *JobQueueServiceImpl) GetJob(jobId string) (JobDetail, error) {
+	record, err := j.store.Get(jobId)
+	if err != nil {
+		return JobDetail{}, err
+	}
+
+	detail := JobDetail{JobRecord: *record, LastError: record.ErrorMessage}
+
+	if rawHistory, ok := record.Metadata[jobAttemptHistoryMetadataKey].([]interface{}); ok {
+		for _, raw := range rawHistory {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			attempt := JobAttempt{}
+			if n, ok := entry["attempt"].(float64); ok {
+				attempt.Attempt = int(n)
+			}
+			attempt.Error, _ = entry["error"].(string)
+			if at, ok := entry["attemptedAt"].(float64); ok {
+				attempt.AttemptedAt = int64(at)
+			}
+			detail.AttemptHistory = append(detail.AttemptHistory, attempt)
+		}
+	}
+
+	if nextAttemptAt, ok := record.Metadata[jobNextAttemptAtMetadataKey].(float64); ok {
+		detail.NextScheduledAt = int64(nextAttemptAt)
+	}
+
+	return detail, nil
+}
+
+// MigrateToLocalStore switches job persistence to an embedded SQLite
+// database at localPath, copying every job and every file in folderId out
+// of Convex first so the local store can resolve jobs on its own. It
+// updates the persisted JobStoreConfig so the switch survives a restart,
+// then starts using the new store immediately. The old store is left
+// untouched in Convex.
+func (j *JobQueueServiceImpl) MigrateToLocalStore(localPath, folderId string) (int, error) {
+	local, err := NewLocalJobStore(localPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open local job store: %w", err)
+	}
+
+	if _, err := MigrateFileRecords(j.convexClient, local, folderId); err != nil {
+		local.Close()
+		return 0, fmt.Errorf("failed to migrate file records: %w", err)
+	}
+
+	migrated, err := MigrateJobStore(j.store, local)
+	if err != nil {
+		local.Close()
+		return migrated, fmt.Errorf("failed to migrate jobs: %w", err)
+	}
+
+	cfg := JobStoreConfig{Backend: JobStoreBackendLocal, LocalPath: localPath}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err == nil {
+		if err := os.WriteFile(jobStoreConfigFile, data, 0644); err != nil {
+			log.Printf("Warning: failed to persist job store config: %v", err)
+		}
+	}
+
+	j.mutex.Lock()
+	j.store = local
+	j.mutex.Unlock()
+
+	return migrated, nil
 }
 
 // Private methods
 
-func (j *JobQueueServiceImpl) processJobQueue() {
-	ticker := time.NewTicker(5 * time.Second) // Check for jobs every 5 seconds
+// processJobQueue polls the job store for pending work and feeds it into
+// the dispatch channel for the worker pool. Rather than polling on a fixed
+// short interval, it blocks between polls until something wakes it: QueueJob
+// (and the fsnotify watch set up by watchPathsForWake) signal j.wake the
+// moment new work might exist, so a locally dropped file reaches a worker in
+// well under a second. jobQueueFallbackPollInterval still fires on its own
+// as a safety net for work that arrives without going through either of
+// those paths (e.g. a job created by another node against a shared store).
+func (j *JobQueueServiceImpl) processJobQueue(ctx context.Context) {
+	ticker := time.NewTicker(jobQueueFallbackPollInterval)
 	defer ticker.Stop()
 
-	for j.isProcessing {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		job, err := j.claimNextJob()
+		if err != nil {
+			log.Printf("Warning: failed to get next job: %v", err)
+			if !waitForWakeOrDone(ctx, j.wake, ticker) {
+				return
+			}
+			continue
+		}
+		if job == nil {
+			if !waitForWakeOrDone(ctx, j.wake, ticker) {
+				return
+			}
+			continue
+		}
+
+		if blocked, reason := j.checkJobBudget(job); blocked {
+			if err := j.blockJobForBudget(job, reason); err != nil {
+				log.Printf("Warning: failed to block job %s for budget: %v", job.ID, err)
+			}
+			continue
+		}
+
+		j.mutex.Lock()
+		if _, inProgress := j.jobsInProgress[job.ID]; inProgress {
+			j.mutex.Unlock()
+			continue
+		}
+		jobCtx, jobCancel := contextForJob(job)
+		j.jobsInProgress[job.ID] = &runningJob{ctx: jobCtx, cancel: jobCancel, startedAt: time.Now()}
+		j.mutex.Unlock()
+
 		select {
-		case <-j.stopChannel:
+		case j.dispatch <- job:
+		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			j.processNextJob()
 		}
+
+		// There may be more pending work queued up behind the job we just
+		// claimed - wake ourselves again so the next claim happens right
+		// away instead of waiting on the fallback ticker.
+		j.wakeNow()
 	}
 }
 
-func (j *JobQueueServiceImpl) processNextJob() {
-	// Get next pending job
-	result, err := j.convexClient.CallQuery("jobs:getNextPending", map[string]interface{}{})
-	if err != nil {
-		log.Printf("Warning: failed to get next job: %v", err)
-		return
+// contextForJob derives job's own cancellable context, honoring the
+// deadline QueueJob stashed at jobTimeoutMetadataKey when JobRequest.TimeoutSec
+// was set. A job with no timeout gets a plain cancellable context, the same
+// as before per-job deadlines existed. The context carries job.ID as its
+// request ID, so every appLog line produced while processing this job -
+// across processParseJob, the parser's Process call, and any Convex calls
+// it makes - is correlatable back to it.
+func contextForJob(job *JobRecord) (context.Context, context.CancelFunc) {
+	base := WithRequestID(context.Background(), job.ID)
+	if raw, ok := job.Metadata[jobTimeoutMetadataKey]; ok {
+		if seconds, ok := raw.(float64); ok && seconds > 0 {
+			return context.WithTimeout(base, time.Duration(seconds)*time.Second)
+		}
 	}
+	return context.WithCancel(base)
+}
 
-	// No jobs available
-	if result == nil {
-		return
+// waitForWakeOrDone blocks until wake fires, ticker fires, or ctx is
+// cancelled, reporting false if ctx was the one that fired.
+func waitForWakeOrDone(ctx context.Context, wake <-chan struct{}, ticker *time.Ticker) bool {
+	select {
+	case <-wake:
+		return true
+	case <-ticker.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
+}
 
-	jobMap, ok := result.(map[string]interface{})
-	if !ok {
-		log.Printf("Warning: invalid job data received")
-		return
+// worker drains the dispatch channel until ctx is cancelled, running each
+// job through runJob so its per-parser and resource-budget gates apply
+// regardless of which worker picks it up.
+func (j *JobQueueServiceImpl) worker(ctx context.Context) {
+	for {
+		select {
+		case job := <-j.dispatch:
+			j.runJob(job)
+		case <-ctx.Done():
+			return
+		}
 	}
+}
 
-	jobId, ok := jobMap["_id"].(string)
-	if !ok {
-		log.Printf("Warning: job missing ID")
-		return
+// runJob gates job behind its parser's MaxConcurrent semaphore and the
+// shared resource budget before handing it to processJob, so the worker
+// pool never runs more of one parser - or more total CPU/GPU/memory - than
+// configured even when every worker is free.
+func (j *JobQueueServiceImpl) runJob(job *JobRecord) {
+	claim := ResourceClaim{CPU: 1, MemMB: 256}
+	maxConcurrent := 1
+	if parser, err := j.parserManager.GetParser(job.ParserID); err == nil {
+		claim = parser.GetResourceClaim()
+		maxConcurrent = parser.GetMaxConcurrent()
 	}
 
-	// Check if job is already being processed
-	j.mutex.RLock()
-	inProgress := j.jobsInProgress[jobId]
-	j.mutex.RUnlock()
+	j.parserSem.acquire(job.ParserID, maxConcurrent)
+	defer j.parserSem.release(job.ParserID, maxConcurrent)
 
-	if inProgress {
-		return
+	for !j.resources.acquire(claim) {
+		time.Sleep(resourceWaitInterval)
 	}
+	defer j.resources.release(claim)
 
-	// Mark job as in progress
-	j.mutex.Lock()
-	j.jobsInProgress[jobId] = true
-	j.mutex.Unlock()
-
-	// Process job in goroutine
-	go j.processJob(jobMap)
+	j.processJob(job)
 }
 
-func (j *JobQueueServiceImpl) processJob(jobData map[string]interface{}) {
-	jobId := jobData["_id"].(string)
+func (j *JobQueueServiceImpl) processJob(job *JobRecord) {
+	j.mutex.RLock()
+	rj := j.jobsInProgress[job.ID]
+	j.mutex.RUnlock()
 
-	// Remove from in-progress when done
 	defer func() {
 		j.mutex.Lock()
-		delete(j.jobsInProgress, jobId)
+		delete(j.jobsInProgress, job.ID)
 		j.mutex.Unlock()
+		rj.cancel()
 	}()
 
-	// Update job status to processing
-	_, err := j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-		"id":        jobId,
-		"status":    "processing",
+	if err := j.store.UpdateStatus(job.ID, "processing", map[string]interface{}{
 		"startedAt": time.Now().Unix() * 1000,
-	})
-	if err != nil {
+	}); err != nil {
 		log.Printf("Error updating job status: %v", err)
 		return
 	}
 
-	// Get job details
-	fileId, _ := jobData["fileId"].(string)
-	parserId, _ := jobData["parserId"].(string)
-	jobType, _ := jobData["jobType"].(string)
+	// Acquire this job's lease and keep it renewed for as long as it runs,
+	// so reapExpiredLeases can tell a worker that crashed mid-job (heartbeat
+	// stopped, lease expires) from one still actively processing it.
+	if err := j.store.UpdateMetadata(job.ID, map[string]interface{}{
+		jobLeaseExpiresAtMetadataKey: time.Now().Add(leaseTTL).UnixMilli(),
+	}); err != nil {
+		log.Printf("Warning: failed to acquire lease for job %s: %v", job.ID, err)
+	}
+	go j.leaseHeartbeat(rj.ctx, job.ID)
 
-	log.Printf("Processing job %s: %s parser for file %s", jobId, parserId, fileId)
+	log.Printf("Processing job %s: %s parser for file %s", job.ID, job.ParserID, job.FileID)
 
 	var jobErr error
-	switch jobType {
+	switch job.JobType {
 	case "parse":
-		jobErr = j.processParseJob(jobId, fileId, parserId)
+		jobErr = j.processParseJob(rj.ctx, job.ID, job.FileID, job.ParserID)
 	case "requeue":
-		jobErr = j.processRequeueJob(jobId, fileId, parserId)
+		jobErr = j.processRequeueJob(rj.ctx, job.ID, job.FileID, job.ParserID)
 	default:
-		jobErr = fmt.Errorf("unknown job type: %s", jobType)
+		jobErr = fmt.Errorf("unknown job type: %s", job.JobType)
+	}
+
+	// If the job's own context was cancelled, CancelJob/PauseJob/the forced-
+	// stop shutdown path already wrote the terminal status - writing
+	// "failed" here would race with (and could overwrite) that status.
+	if errors.Is(jobErr, context.Canceled) {
+		log.Printf("Job %s interrupted", job.ID)
+		return
 	}
 
-	// Update job completion status
 	if jobErr != nil {
-		log.Printf("Job %s failed: %v", jobId, jobErr)
-		_, err = j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-			"id":           jobId,
-			"status":       "failed",
-			"completedAt":  time.Now().Unix() * 1000,
-			"errorMessage": jobErr.Error(),
-		})
+		j.handleJobFailure(job, jobErr)
 	} else {
-		log.Printf("Job %s completed successfully", jobId)
-		_, err = j.convexClient.CallMutation("jobs:updateStatus", map[string]interface{}{
-			"id":          jobId,
-			"status":      "completed",
+		log.Printf("Job %s completed successfully", job.ID)
+		j.terminalStatus(job.ID, "completed", map[string]interface{}{
 			"completedAt": time.Now().Unix() * 1000,
 		})
 	}
-
-	if err != nil {
-		log.Printf("Error updating job completion status: %v", err)
-	}
 }
 
-func (j *JobQueueServiceImpl) processParseJob(jobId, fileId, parserId string) error {
-	// Get file information
-	fileResult, err := j.convexClient.CallQuery("files:getById", map[string]interface{}{
-		"id": fileId,
-	})
+func (j *JobQueueServiceImpl) processParseJob(ctx context.Context, jobId, fileId, parserId string) error {
+	file, err := j.lookupFileRecord(fileId)
 	if err != nil {
-		return fmt.Errorf("failed to get file info: %v", err)
+		return err
 	}
-
-	fileMap, ok := fileResult.(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid file data")
-	}
-
-	filePath, _ := fileMap["path"].(string)
-	fileType, _ := fileMap["fileType"].(string)
+	filePath, fileType := file.Path, file.FileType
 
 	// Get parser
 	parser, err := j.parserManager.GetParser(parserId)
@@ -291,34 +652,125 @@ func (j *JobQueueServiceImpl) processParseJob(jobId, fileId, parserId string) er
 	// Generate output file path
 	outputPath := j.generateOutputPath(filePath, parser)
 
-	// Process the file
-	ctx, cancel := context.WithTimeout(j.ctx, 30*time.Minute)
+	if file.Hash != "" {
+		reused, err := j.reuseCompletedOutput(jobId, fileId, file.Hash, parserId, outputPath)
+		if err != nil {
+			log.Printf("Warning: dedup lookup failed for job %s: %v", jobId, err)
+		} else if reused {
+			return nil
+		}
+	}
+
+	// Process the file, bounded by both the job's own cancellation and an
+	// overall timeout so a runaway parser can't hold its slot forever
+	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
 
-	err = parser.Process(ctx, filePath, outputPath)
+	processStart := time.Now()
+	err = parser.Process(timeoutCtx, filePath, outputPath)
+	parserProcessSeconds.WithLabelValues(parserId).Observe(time.Since(processStart).Seconds())
 	if err != nil {
+		if errors.Is(timeoutCtx.Err(), context.Canceled) && ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("parser processing failed: %v", err)
 	}
 
 	// Update job metadata with output path
-	_, err = j.convexClient.CallMutation("jobs:updateMetadata", map[string]interface{}{
-		"id": jobId,
-		"metadata": map[string]interface{}{
-			"outputPath": outputPath,
-		},
-	})
-	if err != nil {
+	if err := j.store.UpdateMetadata(jobId, map[string]interface{}{
+		"outputPath": outputPath,
+	}); err != nil {
 		log.Printf("Warning: failed to update job metadata: %v", err)
 	}
 
+	if j.costTracking != nil {
+		if cost, err := parser.EstimateCost(filePath); err == nil {
+			j.costTracking.RecordCost(parserId, cost, time.Since(processStart).Seconds(), fileId)
+		}
+	}
+
 	return nil
 }
 
-func (j *JobQueueServiceImpl) processRequeueJob(jobId, fileId, parserId string) error {
+// reuseCompletedOutput looks for a prior job that ran parserId against a
+// file with the same content hash and, if that job's output is still on
+// disk, copies it to outputPath and marks jobId completed without ever
+// calling the parser. It reports reused=false (not an error) whenever
+// there's simply nothing to reuse, so the caller falls through to normal
+// processing - this is a cost-saving fast path, not a guarantee.
+func (j *JobQueueServiceImpl) reuseCompletedOutput(jobId, fileId, fileHash, parserId, outputPath string) (reused bool, err error) {
+	prior, err := j.store.FindCompletedJobByHash(fileHash, parserId)
+	if err != nil {
+		return false, err
+	}
+	if prior == nil || prior.FileID == fileId {
+		return false, nil
+	}
+
+	priorOutputPath, _ := prior.Metadata["outputPath"].(string)
+	if priorOutputPath == "" || priorOutputPath == outputPath {
+		return false, nil
+	}
+
+	if err := copyFile(priorOutputPath, outputPath); err != nil {
+		return false, nil
+	}
+
+	if err := j.store.UpdateMetadata(jobId, map[string]interface{}{
+		"outputPath":  outputPath,
+		"dedupedFrom": prior.ID,
+	}); err != nil {
+		log.Printf("Warning: failed to update job metadata: %v", err)
+	}
+	jobsDedupedTotal.WithLabelValues(parserId).Inc()
+	log.Printf("Job %s reused output from job %s (matching content hash), skipping parser", jobId, prior.ID)
+	return true, nil
+}
+
+// lookupFileRecord resolves fileId to its full FileRecord. When the job
+// store is a LocalJobStore (or any backend that's migrated file records
+// in), it's resolved locally with no Convex round-trip; otherwise it falls
+// back to the files:getById query the job store replaced.
+func (j *JobQueueServiceImpl) lookupFileRecord(fileId string) (*FileRecord, error) {
+	if lookup, ok := j.store.(FileLookup); ok {
+		record, err := lookup.GetFileByID(fileId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file info: %v", err)
+		}
+		return record, nil
+	}
+
+	fileResult, err := j.convexClient.CallQuery("files:getById", map[string]interface{}{
+		"id": fileId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %v", err)
+	}
+
+	fileMap, ok := fileResult.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid file data")
+	}
+
+	record := fileRecordFromMap(fileMap)
+	return &record, nil
+}
+
+// lookupFile resolves fileId to its on-disk path and file type, the subset
+// of lookupFileRecord most callers need.
+func (j *JobQueueServiceImpl) lookupFile(fileId string) (path string, fileType string, err error) {
+	record, err := j.lookupFileRecord(fileId)
+	if err != nil {
+		return "", "", err
+	}
+	return record.Path, record.FileType, nil
+}
+
+func (j *JobQueueServiceImpl) processRequeueJob(ctx context.Context, jobId, fileId, parserId string) error {
 	// For requeue jobs, we simply create a new parse job
 	// This handles cases where output files were deleted
 
-	return j.processParseJob(jobId, fileId, parserId)
+	return j.processParseJob(ctx, jobId, fileId, parserId)
 }
 
 func (j *JobQueueServiceImpl) generateOutputPath(inputPath string, parser Parser) string {
@@ -331,3 +783,22 @@ func (j *JobQueueServiceImpl) generateOutputPath(inputPath string, parser Parser
 	outputName := baseName + suffix + extension
 	return filepath.Join(dir, outputName)
 }
+
+// copyFile copies src to dst, used by reuseCompletedOutput to materialize a
+// deduped job's output without invoking the parser again.
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	_, err = io.Copy(dstFile, srcFile)
+	return err
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statusRetryScheduled marks a job waiting out its exponential backoff delay
+// after a failed attempt, set by handleJobFailure and cleared back to
+// "pending" by advanceScheduledRetries once its nextAttemptAt has passed.
+// It's excluded from GetPendingCountsByParser/GetNextPendingForParser so the
+// scheduler never dispatches it early.
+const statusRetryScheduled = "retry_scheduled"
+
+// statusDeadLetter marks a job that failed maxJobRetries times in a row and
+// won't be retried automatically - it needs a human (or RetryJob) to look at
+// it.
+const statusDeadLetter = "dead_letter"
+
+// maxJobRetries bounds how many times handleJobFailure will reschedule a
+// failed job before dead-lettering it instead.
+const maxJobRetries = 5
+
+// statusBlockedByBudget marks a job checkJobBudget declined to dispatch
+// because its parser's estimated cost would exceed a configured budget. It's
+// cleared back to "pending" by budgetGateSweeper once spend resets (a new
+// day, a new month, or a raised limit) rather than requiring RetryJob.
+const statusBlockedByBudget = "blocked_by_budget"
+
+// jobAttemptsMetadataKey, jobNextAttemptAtMetadataKey, and
+// jobAttemptHistoryMetadataKey are where handleJobFailure stashes retry
+// bookkeeping in job.Metadata, since JobRecord's own columns only track the
+// latest status/error, not the full retry history GetJob exposes.
+const (
+	jobAttemptsMetadataKey       = "attempts"
+	jobNextAttemptAtMetadataKey  = "nextAttemptAt"
+	jobAttemptHistoryMetadataKey = "attemptHistory"
+)
+
+// jobLeaseExpiresAtMetadataKey is where processJob's lease heartbeat stashes
+// the current lease deadline, so reapExpiredLeases can tell a job whose
+// worker crashed (heartbeat stopped, lease expired) from one still being
+// actively worked.
+const jobLeaseExpiresAtMetadataKey = "leaseExpiresAt"
+
+// leaseTTL is how long a claimed job's lease is valid without a renewal
+// before reapExpiredLeases requeues it - long enough that a slow heartbeat
+// tick doesn't false-positive, short enough that a crashed worker's job
+// doesn't sit stuck for long.
+const leaseTTL = 2 * time.Minute
+
+// leaseHeartbeatInterval is how often a running job's lease is renewed, and
+// also the cadence reapExpiredLeases sweeps at.
+const leaseHeartbeatInterval = 30 * time.Second
+
+// retrySweepInterval is how often advanceScheduledRetries checks for
+// retry-scheduled jobs whose backoff delay has elapsed.
+const retrySweepInterval = 5 * time.Second
+
+// fairScheduler implements weighted deficit round robin over parser IDs, so
+// a parser with a deep backlog can't monopolize every worker ahead of
+// others with jobs waiting behind it. Each parser's weight (normally 1,
+// overridden via Parser.GetSchedulerWeight) is the quantum added to its
+// deficit counter every round a dispatch is attempted; a parser is only
+// picked once its accumulated deficit reaches 1, so a weight-2 parser gets
+// roughly twice the dispatches of a weight-1 one over time.
+type fairScheduler struct {
+	mutex    sync.Mutex
+	deficit  map[string]int
+	cursorAt int
+}
+
+func newFairScheduler() *fairScheduler {
+	return &fairScheduler{deficit: make(map[string]int)}
+}
+
+// next picks one parser ID to claim from among pending (parserID -> pending
+// job count), using weights for each parser's quantum. It returns "" if
+// pending is empty or no parser in it has jobs actually waiting.
+func (f *fairScheduler) next(pending map[string]int, weights map[string]int) string {
+	if len(pending) == 0 {
+		return ""
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	// Sorted so the round-robin cursor advances deterministically between
+	// calls instead of depending on Go's randomized map iteration order.
+	sort.Strings(ids)
+
+	// Bounded to 2 full passes over every parser: since every weight is at
+	// least 1, every parser's deficit reaches 1 within one pass, so a
+	// second pass is always enough to find a ready one. This just guards
+	// against an infinite loop if a weight and pending disagree.
+	for round := 0; round < len(ids)*2+1; round++ {
+		if f.cursorAt >= len(ids) {
+			f.cursorAt = 0
+		}
+		id := ids[f.cursorAt]
+
+		if pending[id] <= 0 {
+			// Nothing to dispatch here - don't let deficit accumulate on
+			// a parked id, and move on to give the next one a turn.
+			f.deficit[id] = 0
+			f.cursorAt++
+			continue
+		}
+
+		weight := weights[id]
+		if weight <= 0 {
+			weight = 1
+		}
+		// Only add the quantum once per visit: a deficit already >= 1
+		// means this id is still owed dispatches from a prior visit
+		// that hasn't been fully redeemed yet.
+		if f.deficit[id] < 1 {
+			f.deficit[id] += weight
+		}
+
+		if f.deficit[id] >= 1 {
+			f.deficit[id]--
+			// Stay on this id (don't advance the cursor) as long as its
+			// deficit hasn't been fully redeemed, so a weight-2 parser
+			// gets dispatched twice here for every one dispatch a
+			// weight-1 parser gets - the whole point of DRR's quantum.
+			if f.deficit[id] < 1 {
+				f.cursorAt++
+			}
+			return id
+		}
+	}
+
+	for _, id := range ids {
+		if pending[id] > 0 {
+			return id
+		}
+	}
+	return ""
+}
+
+// claimNextJobFairly picks which parser to claim from next via the weighted
+// fair scheduler, then claims its oldest pending job. Used by claimNextJob
+// when the active store isn't a DistributedJobStore (single-node mode,
+// where a node claims directly rather than racing other nodes for the same
+// job).
+func (j *JobQueueServiceImpl) claimNextJobFairly() (*JobRecord, error) {
+	pending, err := j.store.GetPendingCountsByParser()
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	parserID := j.scheduler.next(pending, j.parserWeights(pending))
+	if parserID == "" {
+		return nil, nil
+	}
+
+	return j.store.GetNextPendingForParser(parserID)
+}
+
+// parserWeights reads each parser with pending work's scheduler weight off
+// its own GetSchedulerWeight, defaulting to 1 for a parser the manager can't
+// currently resolve (e.g. one that was disabled after queuing a job).
+func (j *JobQueueServiceImpl) parserWeights(pending map[string]int) map[string]int {
+	weights := make(map[string]int, len(pending))
+	for parserID := range pending {
+		weight := 1
+		if parser, err := j.parserManager.GetParser(parserID); err == nil {
+			if w := parser.GetSchedulerWeight(); w > 0 {
+				weight = w
+			}
+		}
+		weights[parserID] = weight
+	}
+	return weights
+}
+
+// handleJobFailure decides whether job's failure is worth retrying: if it
+// has fewer than maxJobRetries attempts recorded, it's rescheduled with an
+// exponential backoff delay (reusing the same RetryPolicy the HTTP retry
+// path uses); otherwise it's dead-lettered. Either way the attempt is
+// appended to job's AttemptHistory so GetJob can show the full story.
+func (j *JobQueueServiceImpl) handleJobFailure(job *JobRecord, jobErr error) {
+	attempt := attemptsFromMetadata(job.Metadata) + 1
+	history := appendAttempt(job.Metadata, attempt, jobErr)
+
+	if attempt >= maxJobRetries {
+		log.Printf("Job %s dead-lettered after %d attempts: %v", job.ID, attempt, jobErr)
+		if err := j.store.UpdateMetadata(job.ID, map[string]interface{}{
+			jobAttemptsMetadataKey:       attempt,
+			jobAttemptHistoryMetadataKey: history,
+		}); err != nil {
+			log.Printf("Warning: failed to record attempt history for job %s: %v", job.ID, err)
+		}
+		j.terminalStatus(job.ID, statusDeadLetter, map[string]interface{}{
+			"completedAt":  time.Now().Unix() * 1000,
+			"errorMessage": jobErr.Error(),
+		})
+		return
+	}
+
+	delay := defaultRetryPolicy().delayForAttempt(attempt)
+	nextAttemptAt := time.Now().Add(delay)
+
+	log.Printf("Job %s failed (attempt %d/%d), retrying in %s: %v", job.ID, attempt, maxJobRetries, delay, jobErr)
+
+	if err := j.store.UpdateMetadata(job.ID, map[string]interface{}{
+		jobAttemptsMetadataKey:       attempt,
+		jobNextAttemptAtMetadataKey:  nextAttemptAt.UnixMilli(),
+		jobAttemptHistoryMetadataKey: history,
+	}); err != nil {
+		log.Printf("Warning: failed to record retry bookkeeping for job %s: %v", job.ID, err)
+	}
+
+	if err := j.store.UpdateStatus(job.ID, statusRetryScheduled, map[string]interface{}{
+		"errorMessage": jobErr.Error(),
+	}); err != nil {
+		log.Printf("Warning: failed to schedule retry for job %s: %v", job.ID, err)
+	}
+}
+
+// attemptsFromMetadata reads back the attempt count handleJobFailure last
+// recorded, defaulting to 0 for a job that's never failed before.
+func attemptsFromMetadata(metadata map[string]interface{}) int {
+	if n, ok := metadata[jobAttemptsMetadataKey].(float64); ok {
+		return int(n)
+	}
+	return 0
+}
+
+// appendAttempt returns metadata's existing attemptHistory (if any) with a
+// new entry for this attempt appended.
+func appendAttempt(metadata map[string]interface{}, attempt int, attemptErr error) []interface{} {
+	var history []interface{}
+	if existing, ok := metadata[jobAttemptHistoryMetadataKey].([]interface{}); ok {
+		history = existing
+	}
+	return append(history, map[string]interface{}{
+		"attempt":     attempt,
+		"error":       attemptErr.Error(),
+		"attemptedAt": time.Now().UnixMilli(),
+	})
+}
+
+// retryScheduler sweeps every retrySweepInterval for retry-scheduled jobs
+// whose backoff delay has elapsed, until ctx is cancelled.
+func (j *JobQueueServiceImpl) retryScheduler(ctx context.Context) {
+	ticker := time.NewTicker(retrySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.advanceScheduledRetries()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// advanceScheduledRetries lists every job the store knows about and flips
+// any whose retry-scheduled backoff delay has elapsed back to "pending", so
+// processJobQueue picks it up on the next claim. It's a no-op if the active
+// store doesn't support JobLister, same as advancePipelines.
+func (j *JobQueueServiceImpl) advanceScheduledRetries() {
+	lister, ok := j.store.(JobLister)
+	if !ok {
+		return
+	}
+
+	jobs, err := lister.ListAll()
+	if err != nil {
+		log.Printf("Warning: failed to list jobs for retry scheduling: %v", err)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	requeued := false
+	for _, job := range jobs {
+		if job.Status != statusRetryScheduled {
+			continue
+		}
+		nextAttemptAt, ok := job.Metadata[jobNextAttemptAtMetadataKey].(float64)
+		if ok && int64(nextAttemptAt) > now {
+			continue
+		}
+
+		if err := j.store.UpdateStatus(job.ID, "pending", nil); err != nil {
+			log.Printf("Warning: failed to requeue retry-scheduled job %s: %v", job.ID, err)
+			continue
+		}
+		requeued = true
+	}
+
+	if requeued {
+		j.wakeNow()
+	}
+}
+
+// leaseHeartbeat renews jobId's lease every leaseHeartbeatInterval until ctx
+// (the job's own cancellable context) is done, so reapExpiredLeases can tell
+// this job is still actively being worked. Called once per claimed job from
+// processJob.
+func (j *JobQueueServiceImpl) leaseHeartbeat(ctx context.Context, jobId string) {
+	ticker := time.NewTicker(leaseHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.store.UpdateMetadata(jobId, map[string]interface{}{
+				jobLeaseExpiresAtMetadataKey: time.Now().Add(leaseTTL).UnixMilli(),
+			}); err != nil {
+				log.Printf("Warning: failed to renew lease for job %s: %v", jobId, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// leaseReaperLoop sweeps every leaseHeartbeatInterval for processing jobs
+// whose lease expired with nobody renewing it, until ctx is cancelled.
+func (j *JobQueueServiceImpl) leaseReaperLoop(ctx context.Context) {
+	ticker := time.NewTicker(leaseHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.reapExpiredLeases()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reapExpiredLeases requeues any "processing" job whose lease has expired
+// and that this node isn't currently running itself - meaning the worker
+// that held it (this process before a crash/restart, or one that hung
+// without crashing) stopped renewing it. It's the single-node counterpart
+// to ReapStaleWorkers, which only covers DistributedJobStore's multi-node
+// heartbeats.
+func (j *JobQueueServiceImpl) reapExpiredLeases() {
+	lister, ok := j.store.(JobLister)
+	if !ok {
+		return
+	}
+
+	jobs, err := lister.ListAll()
+	if err != nil {
+		log.Printf("Warning: failed to list jobs for lease reaping: %v", err)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	for _, job := range jobs {
+		if job.Status != "processing" {
+			continue
+		}
+
+		expiresAt, ok := job.Metadata[jobLeaseExpiresAtMetadataKey].(float64)
+		if !ok || int64(expiresAt) > now {
+			continue
+		}
+
+		j.mutex.RLock()
+		_, stillOurs := j.jobsInProgress[job.ID]
+		j.mutex.RUnlock()
+		if stillOurs {
+			continue
+		}
+
+		log.Printf("Job %s: lease expired with no worker renewing it, requeuing", job.ID)
+		if err := j.store.UpdateStatus(job.ID, "pending", nil); err != nil {
+			log.Printf("Warning: failed to requeue job %s past expired lease: %v", job.ID, err)
+			continue
+		}
+		j.wakeNow()
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+// TestFairSchedulerEvenWeightsAlternate verifies that with equal weights,
+// the scheduler cycles through every parser with pending work instead of
+// favoring one over another.
+func TestFairSchedulerEvenWeightsAlternate(t *testing.T) {
+	s := newFairScheduler()
+	pending := map[string]int{"a": 10, "b": 10}
+	weights := map[string]int{"a": 1, "b": 1}
+
+	seen := make(map[string]int)
+	for i := 0; i < 20; i++ {
+		id := s.next(pending, weights)
+		if id == "" {
+			t.Fatalf("next returned empty with pending work available (round %d)", i)
+		}
+		seen[id]++
+	}
+
+	if seen["a"] != seen["b"] {
+		t.Errorf("expected equal dispatch counts for equal weights, got a=%d b=%d", seen["a"], seen["b"])
+	}
+}
+
+// TestFairSchedulerHigherWeightGetsMoreDispatches verifies that a parser
+// with weight 2 is picked roughly twice as often as a weight-1 parser.
+func TestFairSchedulerHigherWeightGetsMoreDispatches(t *testing.T) {
+	s := newFairScheduler()
+	pending := map[string]int{"fast": 100, "slow": 100}
+	weights := map[string]int{"fast": 2, "slow": 1}
+
+	seen := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		id := s.next(pending, weights)
+		seen[id]++
+	}
+
+	if seen["fast"] != 2*seen["slow"] {
+		t.Errorf("expected fast to get twice slow's dispatches, got fast=%d slow=%d", seen["fast"], seen["slow"])
+	}
+}
+
+// TestFairSchedulerSkipsParserWithNoPendingWork verifies that a parser with
+// zero pending jobs is never returned, even though the round-robin cursor
+// still visits it.
+func TestFairSchedulerSkipsParserWithNoPendingWork(t *testing.T) {
+	s := newFairScheduler()
+	pending := map[string]int{"empty": 0, "busy": 5}
+	weights := map[string]int{"empty": 1, "busy": 1}
+
+	for i := 0; i < 10; i++ {
+		id := s.next(pending, weights)
+		if id == "empty" {
+			t.Fatalf("next returned parser with no pending work")
+		}
+	}
+}
+
+// TestFairSchedulerEmptyPendingReturnsEmpty verifies the documented
+// short-circuit for an empty pending map.
+func TestFairSchedulerEmptyPendingReturnsEmpty(t *testing.T) {
+	s := newFairScheduler()
+	if id := s.next(map[string]int{}, map[string]int{}); id != "" {
+		t.Errorf("expected empty string for empty pending map, got %q", id)
+	}
+}
+
+// TestFairSchedulerZeroWeightDefaultsToOne verifies that a parser with no
+// entry in weights (or a non-positive one) still gets dispatched rather
+// than starving forever.
+func TestFairSchedulerZeroWeightDefaultsToOne(t *testing.T) {
+	s := newFairScheduler()
+	pending := map[string]int{"a": 5}
+
+	id := s.next(pending, map[string]int{})
+	if id != "a" {
+		t.Errorf("expected parser with missing weight to default to 1 and be dispatched, got %q", id)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// defaultStatusOutboxPath is the SQLite database the outbox durably records
+// terminal job-status updates in before they're acknowledged by the active
+// JobStore.
+const defaultStatusOutboxPath = "voiceworker_outbox.db"
+
+// outboxRetryInterval is how often the background retrier sweeps for rows
+// whose backoff has elapsed.
+const outboxRetryInterval = 2 * time.Second
+
+// outboxBaseBackoff and outboxMaxBackoff bound the exponential backoff
+// applied to a row's repeated delivery failures.
+const outboxBaseBackoff = 2 * time.Second
+const outboxMaxBackoff = 5 * time.Minute
+
+const statusOutboxSchema = `
+CREATE TABLE IF NOT EXISTS status_outbox (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	job_id          TEXT NOT NULL,
+	status          TEXT NOT NULL,
+	fields          TEXT NOT NULL,
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL,
+	created_at      INTEGER NOT NULL
+);
+`
+
+// outboxRow is a single pending delivery: a terminal status update that
+// hasn't yet been acknowledged by the JobStore.
+type outboxRow struct {
+	id       int64
+	jobId    string
+	status   string
+	fields   map[string]interface{}
+	attempts int
+}
+
+// statusOutbox durably records terminal job-status updates (completed,
+// failed, interrupted) before attempting to apply them, and retries with
+// exponential backoff until the underlying JobStore acknowledges them - so a
+// network blip during the final mutation can no longer leave a job stuck in
+// "processing" forever (previously only recovered by ResetStale on the next
+// app restart). Intermediate updates (the "processing" status, job metadata)
+// aren't outboxed: they're allowed to be lost under backpressure since the
+// terminal update is always delivered eventually and wins.
+type statusOutbox struct {
+	mutex sync.Mutex
+	db    *sql.DB
+}
+
+// newStatusOutbox opens (creating if necessary) the SQLite outbox at path.
+func newStatusOutbox(path string) (*statusOutbox, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open status outbox at %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(statusOutboxSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize status outbox schema: %w", err)
+	}
+
+	return &statusOutbox{db: db}, nil
+}
+
+// enqueue durably records a terminal status update and makes one immediate
+// delivery attempt, so the common case (store reachable) pays no extra
+// latency. If that attempt fails, the row is left in order for the
+// background retrier in run to keep retrying with backoff.
+func (o *statusOutbox) enqueue(store JobStore, jobId, status string, fields map[string]interface{}) {
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		log.Printf("Warning: failed to encode outbox fields for job %s: %v", jobId, err)
+		encoded = []byte("{}")
+	}
+
+	now := time.Now().UnixMilli()
+
+	o.mutex.Lock()
+	result, err := o.db.Exec(
+		`INSERT INTO status_outbox (job_id, status, fields, attempts, next_attempt_at, created_at) VALUES (?, ?, ?, 0, ?, ?)`,
+		jobId, status, string(encoded), now, now,
+	)
+	o.mutex.Unlock()
+	if err != nil {
+		log.Printf("Warning: failed to persist status update for job %s to outbox: %v", jobId, err)
+		return
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Printf("Warning: failed to read outbox row id for job %s: %v", jobId, err)
+		return
+	}
+
+	o.attempt(store, outboxRow{id: id, jobId: jobId, status: status, fields: fields, attempts: 0})
+}
+
+// replay delivers every outstanding outbox row in creation order, so a
+// terminal update written before a crash or restart is applied before any
+// new job status can race ahead of it. Rows that still fail to deliver are
+// left for run's background retrier rather than blocking startup.
+func (o *statusOutbox) replay(store JobStore) {
+	for _, row := range o.pending(`SELECT id, job_id, status, fields, attempts FROM status_outbox ORDER BY id ASC`) {
+		o.attempt(store, row)
+	}
+}
+
+// run sweeps for rows whose backoff has elapsed every outboxRetryInterval
+// until ctx is cancelled.
+func (o *statusOutbox) run(ctx context.Context, store JobStore) {
+	ticker := time.NewTicker(outboxRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			o.sweep(store)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep attempts delivery of every row whose next_attempt_at has elapsed.
+func (o *statusOutbox) sweep(store JobStore) {
+	now := time.Now().UnixMilli()
+	for _, row := range o.pending(`SELECT id, job_id, status, fields, attempts FROM status_outbox WHERE next_attempt_at <= ? ORDER BY id ASC`, now) {
+		o.attempt(store, row)
+	}
+}
+
+// pending runs query and scans every matching row, decoding its JSON
+// fields. Shared by replay and sweep, which only differ in their WHERE
+// clause.
+func (o *statusOutbox) pending(query string, args ...interface{}) []outboxRow {
+	o.mutex.Lock()
+	rows, err := o.db.Query(query, args...)
+	o.mutex.Unlock()
+	if err != nil {
+		log.Printf("Warning: failed to read status outbox: %v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var result []outboxRow
+	for rows.Next() {
+		var row outboxRow
+		var encoded string
+		if err := rows.Scan(&row.id, &row.jobId, &row.status, &encoded, &row.attempts); err != nil {
+			log.Printf("Warning: failed to scan status outbox row: %v", err)
+			continue
+		}
+		if encoded != "" {
+			if err := json.Unmarshal([]byte(encoded), &row.fields); err != nil {
+				log.Printf("Warning: failed to decode status outbox fields for job %s: %v", row.jobId, err)
+			}
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+// attempt applies a single outbox row against store: on success it deletes
+// the row, on failure it bumps attempts and schedules the next try with
+// exponential backoff based on how many attempts have already failed.
+func (o *statusOutbox) attempt(store JobStore, row outboxRow) {
+	if err := store.UpdateStatus(row.jobId, row.status, row.fields); err != nil {
+		nextAttempt := time.Now().Add(backoffFor(row.attempts)).UnixMilli()
+
+		o.mutex.Lock()
+		o.db.Exec(
+			`UPDATE status_outbox SET attempts = attempts + 1, next_attempt_at = ? WHERE id = ?`,
+			nextAttempt, row.id,
+		)
+		o.mutex.Unlock()
+
+		log.Printf("Warning: outbox delivery of %s status for job %s failed (attempt %d), will retry: %v", row.status, row.jobId, row.attempts+1, err)
+		return
+	}
+
+	o.mutex.Lock()
+	o.db.Exec(`DELETE FROM status_outbox WHERE id = ?`, row.id)
+	o.mutex.Unlock()
+}
+
+// backoffFor doubles outboxBaseBackoff per prior attempt, capped at
+// outboxMaxBackoff.
+func backoffFor(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 0; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func (o *statusOutbox) Close() error {
+	return o.db.Close()
+}
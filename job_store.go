@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const jobStoreConfigFile = "job_store_config.json"
+const defaultLocalJobStorePath = "voiceworker_jobs.db"
+
+// JobStoreBackend selects which JobStore implementation JobQueueServiceImpl
+// persists jobs through.
+type JobStoreBackend string
+
+const (
+	JobStoreBackendConvex JobStoreBackend = "convex"
+	JobStoreBackendLocal  JobStoreBackend = "local"
+)
+
+// JobStoreConfig selects and configures the job persistence backend. It is
+// persisted to disk the same way CaptureConfig is, so the choice survives
+// restarts.
+type JobStoreConfig struct {
+	Backend   JobStoreBackend `json:"backend"`
+	LocalPath string          `json:"localPath"`
+}
+
+// defaultJobStoreConfig matches the behavior this app had before the job
+// store became pluggable: everything goes through Convex.
+func defaultJobStoreConfig() JobStoreConfig {
+	return JobStoreConfig{
+		Backend:   JobStoreBackendConvex,
+		LocalPath: defaultLocalJobStorePath,
+	}
+}
+
+// loadJobStoreConfig reads the persisted job store config, falling back to
+// defaultJobStoreConfig if none has been saved yet or it fails to parse.
+func loadJobStoreConfig() JobStoreConfig {
+	cfg := defaultJobStoreConfig()
+
+	data, err := os.ReadFile(jobStoreConfigFile)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Printf("Error parsing job store config file: %v\n", err)
+		return defaultJobStoreConfig()
+	}
+	if cfg.LocalPath == "" {
+		cfg.LocalPath = defaultLocalJobStorePath
+	}
+
+	return cfg
+}
+
+// JobRecord is a backend-agnostic view of a persisted job: every field
+// JobQueueServiceImpl reads off a job regardless of which JobStore produced
+// it.
+type JobRecord struct {
+	ID           string
+	FileID       string
+	ParserID     string
+	JobType      string
+	Status       string
+	Priority     int
+	Metadata     map[string]interface{}
+	StartedAt    int64
+	CompletedAt  int64
+	ErrorMessage string
+	CreatedAt    int64
+}
+
+// FileRecord is the subset of a Convex files:* document processParseJob
+// needs to run a parser against a file: its on-disk path and detected file
+// type. It exists so LocalJobStore can resolve files without a Convex
+// deployment once it holds a migrated copy.
+type FileRecord struct {
+	ID       string
+	Path     string
+	FileType string
+	FolderID string
+
+	// Hash is the file's content hash (see calculateFileHash), used as the
+	// dedup key processParseJob checks against the processing cache before
+	// paying a parser to redo work an unchanged file already has output for.
+	Hash string
+}
+
+// JobStore persists jobs for JobQueueServiceImpl. ConvexJobStore is the
+// default, calling the existing Convex jobs:* functions; LocalJobStore is an
+// embedded SQLite-backed alternative so the app can run fully offline.
+// JobQueueServiceImpl only ever talks to this interface, so switching
+// backends is a config change, not a code change.
+type JobStore interface {
+	Create(job JobRequest) (string, error)
+	UpdateStatus(jobId, status string, fields map[string]interface{}) error
+	UpdateMetadata(jobId string, metadata map[string]interface{}) error
+	GetNextPending() (*JobRecord, error)
+	GetQueueStats() (QueueStatus, error)
+	ResetStale(olderThan time.Duration) error
+	Close() error
+
+	// Get returns a single job by ID, used by JobQueueServiceImpl.GetJob to
+	// expose a job's full detail beyond the aggregate GetQueueStats counts.
+	Get(jobId string) (*JobRecord, error)
+
+	// GetPendingCountsByParser returns how many pending jobs are waiting per
+	// parser ID, letting the weighted fair scheduler see which parsers
+	// actually have backlog before it spends a round on one.
+	GetPendingCountsByParser() (map[string]int, error)
+
+	// GetNextPendingForParser is like GetNextPending but scoped to a single
+	// parser, so the weighted fair scheduler can claim from whichever
+	// parser it picked without pulling the oldest job queue-wide.
+	GetNextPendingForParser(parserId string) (*JobRecord, error)
+
+	// GetQueueStatsByParser breaks GetQueueStats's counts down per parser
+	// ID, populating QueueStatus.PerParser.
+	GetQueueStatsByParser() (map[string]ParserQueueBreakdown, error)
+
+	// FindCompletedJobByHash returns the most recently completed job that
+	// ran parserId against a file whose content hash matches fileHash, or
+	// nil if none exists. processParseJob uses it to recognize a file by
+	// content rather than by FileID, so a duplicate, renamed, or
+	// re-discovered copy of a file that's already been processed reuses
+	// that prior output instead of paying the parser to redo the work.
+	FindCompletedJobByHash(fileHash, parserId string) (*JobRecord, error)
+}
+
+// ParserQueueBreakdown is one parser's slice of QueueStatus's aggregate
+// counts.
+type ParserQueueBreakdown struct {
+	PendingJobs    int `json:"pendingJobs"`
+	ProcessingJobs int `json:"processingJobs"`
+	CompletedJobs  int `json:"completedJobs"`
+	FailedJobs     int `json:"failedJobs"`
+}
+
+// JobLister is implemented by JobStore backends that can enumerate every job
+// they hold. It's split out from JobStore since only MigrateJobStore and the
+// background sweeps (advanceScheduledRetries, reapExpiredLeases,
+// budgetGateSweeper, advancePipelines) need a full listing -
+// processJobQueue's hot path never does.
+type JobLister interface {
+	ListAll() ([]JobRecord, error)
+}
+
+// FileLookup is implemented by JobStore backends that can resolve a file
+// record by ID without Convex, so processParseJob can run against a
+// LocalJobStore that's been populated via MigrateFileRecords.
+type FileLookup interface {
+	GetFileByID(id string) (*FileRecord, error)
+}
+
+// WorkerPeer is a node cooperating on the same Convex-backed job queue, as
+// reported by workers:listPeers.
+type WorkerPeer struct {
+	NodeID        string
+	Capabilities  map[string]interface{}
+	ClaimedJobs   int
+	LastHeartbeat int64
+}
+
+// DistributedJobStore is implemented by JobStore backends that support
+// multiple cooperating nodes sharing one queue - ConvexJobStore, since
+// LocalJobStore's embedded SQLite database is inherently single-node.
+// JobQueueServiceImpl only engages claim/heartbeat/reap behavior when the
+// active store implements this, so a single-node local deployment pays
+// none of the distributed-mode overhead.
+type DistributedJobStore interface {
+	// ClaimNextPending atomically claims the next pending job for nodeId,
+	// preferring one whose file lives under pathPrefixes when a deployment
+	// routes by local filesystem access rather than a shared mount.
+	ClaimNextPending(nodeId string, pathPrefixes []string) (*JobRecord, error)
+
+	// Heartbeat reports nodeId's liveness, the jobs it currently holds, and
+	// its capabilities (e.g. {"gpu": true, "parsers": [...]}).
+	Heartbeat(nodeId string, claimedJobIds []string, capabilities map[string]interface{}) error
+
+	// ReapStaleWorkers requeues every job still assigned to a node that's
+	// missed missedHeartbeats consecutive heartbeats, returning how many
+	// jobs were requeued.
+	ReapStaleWorkers(missedHeartbeats int) (int, error)
+
+	// ListPeers returns every node currently cooperating on this queue.
+	ListPeers() ([]WorkerPeer, error)
+}
+
+// FileRegistrar is implemented by JobStore backends that can mint a new
+// file record for a path that didn't exist at startup, so a pipeline spec
+// run can register an intermediate stage's output and queue the next
+// stage against it the same way any other file is queued.
+type FileRegistrar interface {
+	RegisterFile(file FileRecord) (string, error)
+}
+
+// NewJobStore builds the JobStore selected by the persisted JobStoreConfig.
+// convexClient may be nil when the config selects the local backend.
+func NewJobStore(convexClient *ConvexClient) (JobStore, error) {
+	cfg := loadJobStoreConfig()
+
+	switch cfg.Backend {
+	case JobStoreBackendLocal:
+		return NewLocalJobStore(cfg.LocalPath)
+	case JobStoreBackendConvex, "":
+		return NewConvexJobStore(convexClient), nil
+	default:
+		return nil, fmt.Errorf("unknown job store backend: %q", cfg.Backend)
+	}
+}
+
+// MigrateJobStore copies every job from src to dst and reports how many were
+// copied. It's meant for a one-time switch between backends (e.g. going
+// fully offline) - callers decide whether to keep or retire src afterward.
+func MigrateJobStore(src, dst JobStore) (int, error) {
+	lister, ok := src.(JobLister)
+	if !ok {
+		return 0, fmt.Errorf("source job store does not support listing jobs for migration")
+	}
+
+	jobs, err := lister.ListAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list jobs from source store: %w", err)
+	}
+
+	migrated := 0
+	for _, job := range jobs {
+		newId, err := dst.Create(JobRequest{
+			FileID:   job.FileID,
+			ParserID: job.ParserID,
+			JobType:  job.JobType,
+			Priority: job.Priority,
+			Metadata: job.Metadata,
+		})
+		if err != nil {
+			return migrated, fmt.Errorf("failed to migrate job %s: %w", job.ID, err)
+		}
+
+		fields := map[string]interface{}{}
+		if job.StartedAt != 0 {
+			fields["startedAt"] = job.StartedAt
+		}
+		if job.CompletedAt != 0 {
+			fields["completedAt"] = job.CompletedAt
+		}
+		if job.ErrorMessage != "" {
+			fields["errorMessage"] = job.ErrorMessage
+		}
+		if err := dst.UpdateStatus(newId, job.Status, fields); err != nil {
+			return migrated, fmt.Errorf("failed to migrate status for job %s: %w", job.ID, err)
+		}
+
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// MigrateFileRecords copies every file record in folderId from Convex into
+// dst's local file table, so a LocalJobStore has enough file metadata to
+// resolve processParseJob's file lookups once the job queue is fully
+// switched to the local backend. It's a one-shot export, not a live sync.
+func MigrateFileRecords(convexClient *ConvexClient, dst *LocalJobStore, folderId string) (int, error) {
+	result, err := convexClient.CallQuery("files:getByFolder", map[string]interface{}{"folderId": folderId})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files from Convex: %w", err)
+	}
+
+	list, ok := result.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("invalid file list received")
+	}
+
+	migrated := 0
+	for _, item := range list {
+		fileMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := dst.UpsertFile(fileRecordFromMap(fileMap)); err != nil {
+			return migrated, fmt.Errorf("failed to migrate file %v: %w", fileMap["_id"], err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// ConvexJobStore persists jobs via the Convex jobs:* functions - the backend
+// JobQueueServiceImpl used exclusively before job persistence became
+// pluggable.
+type ConvexJobStore struct {
+	convexClient *ConvexClient
+}
+
+// NewConvexJobStore wraps convexClient as a JobStore.
+func NewConvexJobStore(convexClient *ConvexClient) *ConvexJobStore {
+	return &ConvexJobStore{convexClient: convexClient}
+}
+
+// jobsCreateConvexFunction is the Convex function job creation calls -
+// named here (rather than only inline below) so QueueJob can check this
+// specific endpoint's CircuitBreaker before attempting a create at all.
+const jobsCreateConvexFunction = "jobs:create"
+
+func (s *ConvexJobStore) Create(job JobRequest) (string, error) {
+	result, err := s.convexClient.CallMutation(jobsCreateConvexFunction, map[string]interface{}{
+		"fileId":   job.FileID,
+		"parserId": job.ParserID,
+		"jobType":  job.JobType,
+		"status":   "pending",
+		"priority": job.Priority,
+		"metadata": job.Metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+func (s *ConvexJobStore) UpdateStatus(jobId, status string, fields map[string]interface{}) error {
+	args := map[string]interface{}{
+		"id":     jobId,
+		"status": status,
+	}
+	for k, v := range fields {
+		args[k] = v
+	}
+
+	_, err := s.convexClient.CallMutation("jobs:updateStatus", args)
+	return err
+}
+
+func (s *ConvexJobStore) UpdateMetadata(jobId string, metadata map[string]interface{}) error {
+	_, err := s.convexClient.CallMutation("jobs:updateMetadata", map[string]interface{}{
+		"id":       jobId,
+		"metadata": metadata,
+	})
+	return err
+}
+
+func (s *ConvexJobStore) GetNextPending() (*JobRecord, error) {
+	result, err := s.convexClient.CallQuery("jobs:getNextPending", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	jobMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job data received")
+	}
+
+	return jobRecordFromMap(jobMap), nil
+}
+
+func (s *ConvexJobStore) GetQueueStats() (QueueStatus, error) {
+	result, err := s.convexClient.CallQuery("jobs:getQueueStats", map[string]interface{}{})
+	if err != nil {
+		return QueueStatus{}, err
+	}
+
+	status := QueueStatus{}
+	statsMap, ok := result.(map[string]interface{})
+	if !ok {
+		return status, nil
+	}
+
+	if total, ok := statsMap["total"].(float64); ok {
+		status.TotalJobs = int(total)
+	}
+	if pending, ok := statsMap["pending"].(float64); ok {
+		status.PendingJobs = int(pending)
+	}
+	if processing, ok := statsMap["processing"].(float64); ok {
+		status.ProcessingJobs = int(processing)
+	}
+	if completed, ok := statsMap["completed"].(float64); ok {
+		status.CompletedJobs = int(completed)
+	}
+	if failed, ok := statsMap["failed"].(float64); ok {
+		status.FailedJobs = int(failed)
+	}
+	if paused, ok := statsMap["paused"].(float64); ok {
+		status.PausedJobs = int(paused)
+	}
+	if retryScheduled, ok := statsMap["retryScheduled"].(float64); ok {
+		status.RetryScheduledJobs = int(retryScheduled)
+	}
+	if deadLetter, ok := statsMap["deadLetter"].(float64); ok {
+		status.DeadLetterJobs = int(deadLetter)
+	}
+	if blockedByBudget, ok := statsMap["blockedByBudget"].(float64); ok {
+		status.BlockedByBudgetJobs = int(blockedByBudget)
+	}
+
+	return status, nil
+}
+
+// GetPendingCountsByParser satisfies JobStore via jobs:getPendingCountsByParser,
+// feeding the weighted fair scheduler which parsers actually have backlog.
+func (s *ConvexJobStore) GetPendingCountsByParser() (map[string]int, error) {
+	result, err := s.convexClient.CallQuery("jobs:getPendingCountsByParser", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	countsMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	counts := make(map[string]int, len(countsMap))
+	for parserID, raw := range countsMap {
+		if count, ok := raw.(float64); ok {
+			counts[parserID] = int(count)
+		}
+	}
+	return counts, nil
+}
+
+// GetNextPendingForParser satisfies JobStore via jobs:getNextPendingForParser,
+// scoping GetNextPending's query to a single parser for the weighted fair
+// scheduler.
+func (s *ConvexJobStore) GetNextPendingForParser(parserId string) (*JobRecord, error) {
+	result, err := s.convexClient.CallQuery("jobs:getNextPendingForParser", map[string]interface{}{
+		"parserId": parserId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	jobMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job data received")
+	}
+	return jobRecordFromMap(jobMap), nil
+}
+
+// GetQueueStatsByParser satisfies JobStore via jobs:getQueueStatsByParser,
+// populating QueueStatus.PerParser.
+func (s *ConvexJobStore) GetQueueStatsByParser() (map[string]ParserQueueBreakdown, error) {
+	result, err := s.convexClient.CallQuery("jobs:getQueueStatsByParser", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	byParser, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	breakdowns := make(map[string]ParserQueueBreakdown, len(byParser))
+	for parserID, raw := range byParser {
+		statsMap, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		b := ParserQueueBreakdown{}
+		if v, ok := statsMap["pending"].(float64); ok {
+			b.PendingJobs = int(v)
+		}
+		if v, ok := statsMap["processing"].(float64); ok {
+			b.ProcessingJobs = int(v)
+		}
+		if v, ok := statsMap["completed"].(float64); ok {
+			b.CompletedJobs = int(v)
+		}
+		if v, ok := statsMap["failed"].(float64); ok {
+			b.FailedJobs = int(v)
+		}
+		breakdowns[parserID] = b
+	}
+	return breakdowns, nil
+}
+
+// FindCompletedJobByHash satisfies JobStore via jobs:findCompletedByHash,
+// which does the fileHash-to-file-to-job join server-side.
+func (s *ConvexJobStore) FindCompletedJobByHash(fileHash, parserId string) (*JobRecord, error) {
+	result, err := s.convexClient.CallQuery("jobs:findCompletedByHash", map[string]interface{}{
+		"fileHash": fileHash,
+		"parserId": parserId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	jobMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job data received")
+	}
+	return jobRecordFromMap(jobMap), nil
+}
+
+// Get satisfies JobStore via jobs:get, used by GetJob to return a single
+// job's full detail.
+func (s *ConvexJobStore) Get(jobId string) (*JobRecord, error) {
+	result, err := s.convexClient.CallQuery("jobs:get", map[string]interface{}{
+		"id": jobId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("job not found: %s", jobId)
+	}
+
+	jobMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job data received")
+	}
+	return jobRecordFromMap(jobMap), nil
+}
+
+// ResetStale marks any job that has been "processing" for longer than
+// olderThan back to "pending" via jobs:resetStale, recovering jobs orphaned
+// by a crash mid-process.
+func (s *ConvexJobStore) ResetStale(olderThan time.Duration) error {
+	_, err := s.convexClient.CallMutation("jobs:resetStale", map[string]interface{}{
+		"olderThanMs": olderThan.Milliseconds(),
+	})
+	return err
+}
+
+// ClaimNextPending satisfies DistributedJobStore via jobs:claim, which does
+// a compare-and-set on the job's assignedNode server-side so two nodes
+// racing for the same pending job can't both end up processing it.
+func (s *ConvexJobStore) ClaimNextPending(nodeId string, pathPrefixes []string) (*JobRecord, error) {
+	result, err := s.convexClient.CallMutation("jobs:claim", map[string]interface{}{
+		"nodeId":       nodeId,
+		"pathPrefixes": pathPrefixes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	jobMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job data received")
+	}
+	return jobRecordFromMap(jobMap), nil
+}
+
+// Heartbeat satisfies DistributedJobStore via workers:heartbeat, so
+// ReapStaleWorkers elsewhere in the cluster can tell a busy node from one
+// that's gone silent.
+func (s *ConvexJobStore) Heartbeat(nodeId string, claimedJobIds []string, capabilities map[string]interface{}) error {
+	_, err := s.convexClient.CallMutation("workers:heartbeat", map[string]interface{}{
+		"nodeId":        nodeId,
+		"claimedJobIds": claimedJobIds,
+		"capabilities":  capabilities,
+	})
+	return err
+}
+
+// ReapStaleWorkers satisfies DistributedJobStore via workers:reapStale.
+func (s *ConvexJobStore) ReapStaleWorkers(missedHeartbeats int) (int, error) {
+	result, err := s.convexClient.CallMutation("workers:reapStale", map[string]interface{}{
+		"missedHeartbeats": missedHeartbeats,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if count, ok := result.(float64); ok {
+		return int(count), nil
+	}
+	return 0, nil
+}
+
+// ListPeers satisfies DistributedJobStore via workers:listPeers, so
+// GetQueueStatus can aggregate cluster-wide state instead of only this
+// node's.
+func (s *ConvexJobStore) ListPeers() ([]WorkerPeer, error) {
+	result, err := s.convexClient.CallQuery("workers:listPeers", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid worker peer list received")
+	}
+
+	peers := make([]WorkerPeer, 0, len(list))
+	for _, item := range list {
+		peerMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		peers = append(peers, workerPeerFromMap(peerMap))
+	}
+	return peers, nil
+}
+
+// workerPeerFromMap parses the map[string]interface{} shape a
+// workers:listPeers query result comes back as into a WorkerPeer.
+func workerPeerFromMap(peerMap map[string]interface{}) WorkerPeer {
+	peer := WorkerPeer{}
+	peer.NodeID, _ = peerMap["nodeId"].(string)
+	if caps, ok := peerMap["capabilities"].(map[string]interface{}); ok {
+		peer.Capabilities = caps
+	}
+	if claimed, ok := peerMap["claimedJobs"].(float64); ok {
+		peer.ClaimedJobs = int(claimed)
+	}
+	if lastHeartbeat, ok := peerMap["lastHeartbeat"].(float64); ok {
+		peer.LastHeartbeat = int64(lastHeartbeat)
+	}
+	return peer
+}
+
+// RegisterFile satisfies FileRegistrar via files:create, the same mutation
+// FileWatcherServiceImpl uses to record a newly discovered file - here
+// used to register a pipeline spec run's intermediate stage output so the
+// next stage can be queued against it.
+func (s *ConvexJobStore) RegisterFile(file FileRecord) (string, error) {
+	result, err := s.convexClient.CallMutation("files:create", map[string]interface{}{
+		"path":      file.Path,
+		"name":      filepath.Base(file.Path),
+		"folderId":  file.FolderID,
+		"sizeBytes": int64(0),
+		"fileType":  file.FileType,
+		"extension": filepath.Ext(file.Path),
+		"status":    "unprocessed",
+		"hash":      "",
+		"isOutput":  true,
+		"metadata":  map[string]interface{}{},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := result.(string)
+	return id, nil
+}
+
+// ListAll returns every job via jobs:listAll, used by MigrateJobStore when
+// moving off the Convex backend.
+func (s *ConvexJobStore) ListAll() ([]JobRecord, error) {
+	result, err := s.convexClient.CallQuery("jobs:listAll", map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid job list received")
+	}
+
+	records := make([]JobRecord, 0, len(list))
+	for _, item := range list {
+		jobMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		records = append(records, *jobRecordFromMap(jobMap))
+	}
+
+	return records, nil
+}
+
+func (s *ConvexJobStore) Close() error {
+	return nil
+}
+
+// jobRecordFromMap parses the map[string]interface{} shape a Convex job
+// query result comes back as into a JobRecord.
+func jobRecordFromMap(jobMap map[string]interface{}) *JobRecord {
+	record := &JobRecord{}
+	record.ID, _ = jobMap["_id"].(string)
+	record.FileID, _ = jobMap["fileId"].(string)
+	record.ParserID, _ = jobMap["parserId"].(string)
+	record.JobType, _ = jobMap["jobType"].(string)
+	record.Status, _ = jobMap["status"].(string)
+	if priority, ok := jobMap["priority"].(float64); ok {
+		record.Priority = int(priority)
+	}
+	if metadata, ok := jobMap["metadata"].(map[string]interface{}); ok {
+		record.Metadata = metadata
+	}
+	if startedAt, ok := jobMap["startedAt"].(float64); ok {
+		record.StartedAt = int64(startedAt)
+	}
+	if completedAt, ok := jobMap["completedAt"].(float64); ok {
+		record.CompletedAt = int64(completedAt)
+	}
+	record.ErrorMessage, _ = jobMap["errorMessage"].(string)
+	if createdAt, ok := jobMap["createdAt"].(float64); ok {
+		record.CreatedAt = int64(createdAt)
+	}
+	return record
+}
+
+// fileRecordFromMap parses the map[string]interface{} shape a Convex file
+// query result comes back as into a FileRecord.
+func fileRecordFromMap(fileMap map[string]interface{}) FileRecord {
+	record := FileRecord{}
+	record.ID, _ = fileMap["_id"].(string)
+	record.Path, _ = fileMap["path"].(string)
+	record.FileType, _ = fileMap["fileType"].(string)
+	record.Hash, _ = fileMap["hash"].(string)
+	return record
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// localJobStoreSchema creates the jobs and files tables on first use. Job
+// IDs are minted from a nanosecond timestamp since there's no Convex
+// document ID to borrow here.
+const localJobStoreSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id            TEXT PRIMARY KEY,
+	file_id       TEXT NOT NULL,
+	parser_id     TEXT NOT NULL,
+	job_type      TEXT NOT NULL,
+	status        TEXT NOT NULL,
+	priority      INTEGER NOT NULL DEFAULT 0,
+	metadata      TEXT NOT NULL DEFAULT '{}',
+	started_at    INTEGER NOT NULL DEFAULT 0,
+	completed_at  INTEGER NOT NULL DEFAULT 0,
+	error_message TEXT NOT NULL DEFAULT '',
+	created_at    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS jobs_status_priority_idx ON jobs(status, priority DESC, created_at);
+CREATE INDEX IF NOT EXISTS jobs_parser_status_idx ON jobs(parser_id, status, priority DESC, created_at);
+
+CREATE TABLE IF NOT EXISTS files (
+	id        TEXT PRIMARY KEY,
+	path      TEXT NOT NULL,
+	file_type TEXT NOT NULL,
+	hash      TEXT NOT NULL DEFAULT ''
+);
+`
+
+// LocalJobStore is an embedded, Convex-free JobStore backed by a SQLite
+// database file via the pure-Go modernc.org/sqlite driver (no cgo, so it
+// cross-compiles the same way the rest of this app does), letting the app
+// run fully offline.
+type LocalJobStore struct {
+	db *sql.DB
+}
+
+// NewLocalJobStore opens (creating if necessary) the SQLite database at
+// path and ensures its schema exists.
+func NewLocalJobStore(path string) (*LocalJobStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local job store at %s: %w", path, err)
+	}
+
+	if _, err := db.Exec(localJobStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize local job store schema: %w", err)
+	}
+
+	return &LocalJobStore{db: db}, nil
+}
+
+func (s *LocalJobStore) Create(job JobRequest) (string, error) {
+	id := fmt.Sprintf("job_%d", time.Now().UnixNano())
+
+	metadata, err := json.Marshal(job.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job metadata: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO jobs (id, file_id, parser_id, job_type, status, priority, metadata, created_at) VALUES (?, ?, ?, ?, 'pending', ?, ?, ?)`,
+		id, job.FileID, job.ParserID, job.JobType, job.Priority, string(metadata), time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert job: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *LocalJobStore) UpdateStatus(jobId, status string, fields map[string]interface{}) error {
+	startedAt, _ := fields["startedAt"].(int64)
+	completedAt, _ := fields["completedAt"].(int64)
+	errorMessage, _ := fields["errorMessage"].(string)
+
+	_, err := s.db.Exec(
+		`UPDATE jobs SET status = ?,
+			started_at = CASE WHEN ? != 0 THEN ? ELSE started_at END,
+			completed_at = CASE WHEN ? != 0 THEN ? ELSE completed_at END,
+			error_message = CASE WHEN ? != '' THEN ? ELSE error_message END
+		WHERE id = ?`,
+		status, startedAt, startedAt, completedAt, completedAt, errorMessage, errorMessage, jobId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job %s status: %w", jobId, err)
+	}
+	return nil
+}
+
+// UpdateMetadata merges metadata into jobId's existing metadata rather than
+// replacing it outright, so e.g. processParseJob recording outputPath
+// doesn't clobber whatever SubmitPipelineSpec recorded on the job at
+// Create time (and vice versa for forceStopRemaining's checkpoint).
+func (s *LocalJobStore) UpdateMetadata(jobId string, metadata map[string]interface{}) error {
+	row := s.db.QueryRow(`SELECT metadata FROM jobs WHERE id = ?`, jobId)
+
+	var existingEncoded string
+	if err := row.Scan(&existingEncoded); err != nil {
+		return fmt.Errorf("failed to read job %s metadata: %w", jobId, err)
+	}
+
+	merged := map[string]interface{}{}
+	if existingEncoded != "" {
+		if err := json.Unmarshal([]byte(existingEncoded), &merged); err != nil {
+			return fmt.Errorf("failed to decode job %s metadata: %w", jobId, err)
+		}
+	}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("failed to encode job metadata: %w", err)
+	}
+
+	if _, err := s.db.Exec(`UPDATE jobs SET metadata = ? WHERE id = ?`, string(encoded), jobId); err != nil {
+		return fmt.Errorf("failed to update job %s metadata: %w", jobId, err)
+	}
+	return nil
+}
+
+func (s *LocalJobStore) GetNextPending() (*JobRecord, error) {
+	row := s.db.QueryRow(
+		`SELECT id, file_id, parser_id, job_type, status, priority, metadata, started_at, completed_at, error_message, created_at
+		 FROM jobs WHERE status = 'pending' ORDER BY priority DESC, created_at ASC LIMIT 1`,
+	)
+
+	record, err := scanJobRow(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query next pending job: %w", err)
+	}
+	return record, nil
+}
+
+func (s *LocalJobStore) GetQueueStats() (QueueStatus, error) {
+	rows, err := s.db.Query(`SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return QueueStatus{}, fmt.Errorf("failed to query queue stats: %w", err)
+	}
+	defer rows.Close()
+
+	status := QueueStatus{}
+	for rows.Next() {
+		var jobStatus string
+		var count int
+		if err := rows.Scan(&jobStatus, &count); err != nil {
+			return QueueStatus{}, err
+		}
+		status.TotalJobs += count
+		switch jobStatus {
+		case "pending":
+			status.PendingJobs = count
+		case "processing":
+			status.ProcessingJobs = count
+		case "completed":
+			status.CompletedJobs = count
+		case "failed":
+			status.FailedJobs = count
+		case "paused":
+			status.PausedJobs = count
+		case statusRetryScheduled:
+			status.RetryScheduledJobs = count
+		case statusDeadLetter:
+			status.DeadLetterJobs = count
+		case statusBlockedByBudget:
+			status.BlockedByBudgetJobs = count
+		}
+	}
+
+	return status, rows.Err()
+}
+
+// GetPendingCountsByParser satisfies JobStore for the weighted fair
+// scheduler, grouping the same "pending" count GetNextPending draws from by
+// parser_id.
+func (s *LocalJobStore) GetPendingCountsByParser() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT parser_id, COUNT(*) FROM jobs WHERE status = 'pending' GROUP BY parser_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending counts by parser: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var parserID string
+		var count int
+		if err := rows.Scan(&parserID, &count); err != nil {
+			return nil, err
+		}
+		counts[parserID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetNextPendingForParser satisfies JobStore, scoping GetNextPending's query
+// to a single parser ID for the weighted fair scheduler.
+func (s *LocalJobStore) GetNextPendingForParser(parserId string) (*JobRecord, error) {
+	row := s.db.QueryRow(
+		`SELECT id, file_id, parser_id, job_type, status, priority, metadata, started_at, completed_at, error_message, created_at
+		 FROM jobs WHERE status = 'pending' AND parser_id = ? ORDER BY priority DESC, created_at ASC LIMIT 1`,
+		parserId,
+	)
+
+	record, err := scanJobRow(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query next pending job for parser %s: %w", parserId, err)
+	}
+	return record, nil
+}
+
+// GetQueueStatsByParser satisfies JobStore, breaking down the same statuses
+// GetQueueStats counts by parser_id for QueueStatus.PerParser.
+func (s *LocalJobStore) GetQueueStatsByParser() (map[string]ParserQueueBreakdown, error) {
+	rows, err := s.db.Query(`SELECT parser_id, status, COUNT(*) FROM jobs GROUP BY parser_id, status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query queue stats by parser: %w", err)
+	}
+	defer rows.Close()
+
+	breakdowns := make(map[string]ParserQueueBreakdown)
+	for rows.Next() {
+		var parserID, jobStatus string
+		var count int
+		if err := rows.Scan(&parserID, &jobStatus, &count); err != nil {
+			return nil, err
+		}
+
+		b := breakdowns[parserID]
+		switch jobStatus {
+		case "pending":
+			b.PendingJobs = count
+		case "processing":
+			b.ProcessingJobs = count
+		case "completed":
+			b.CompletedJobs = count
+		case "failed":
+			b.FailedJobs = count
+		}
+		breakdowns[parserID] = b
+	}
+	return breakdowns, rows.Err()
+}
+
+// FindCompletedJobByHash satisfies JobStore, joining jobs to files on
+// file_id so a dedup lookup can be keyed by content hash instead of a
+// specific FileID.
+func (s *LocalJobStore) FindCompletedJobByHash(fileHash, parserId string) (*JobRecord, error) {
+	row := s.db.QueryRow(
+		`SELECT j.id, j.file_id, j.parser_id, j.job_type, j.status, j.priority, j.metadata, j.started_at, j.completed_at, j.error_message, j.created_at
+		 FROM jobs j JOIN files f ON f.id = j.file_id
+		 WHERE f.hash = ? AND j.parser_id = ? AND j.status = 'completed'
+		 ORDER BY j.completed_at DESC LIMIT 1`,
+		fileHash, parserId,
+	)
+
+	record, err := scanJobRow(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed job by hash: %w", err)
+	}
+	return record, nil
+}
+
+// Get satisfies JobStore, used by GetJob to return a single job's full
+// detail.
+func (s *LocalJobStore) Get(jobId string) (*JobRecord, error) {
+	row := s.db.QueryRow(
+		`SELECT id, file_id, parser_id, job_type, status, priority, metadata, started_at, completed_at, error_message, created_at
+		 FROM jobs WHERE id = ?`,
+		jobId,
+	)
+
+	record, err := scanJobRow(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", jobId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job %s: %w", jobId, err)
+	}
+	return record, nil
+}
+
+func (s *LocalJobStore) ResetStale(olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan).UnixMilli()
+	_, err := s.db.Exec(`UPDATE jobs SET status = 'pending' WHERE status = 'processing' AND started_at < ?`, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to reset stale jobs: %w", err)
+	}
+	return nil
+}
+
+// ListAll returns every job, ordered oldest-first, for MigrateJobStore.
+func (s *LocalJobStore) ListAll() ([]JobRecord, error) {
+	rows, err := s.db.Query(
+		`SELECT id, file_id, parser_id, job_type, status, priority, metadata, started_at, completed_at, error_message, created_at
+		 FROM jobs ORDER BY created_at ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var records []JobRecord
+	for rows.Next() {
+		record, err := scanJobRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// UpsertFile stores or replaces file, so GetFileByID can resolve it without
+// Convex. Used by MigrateFileRecords and by a future local-only scan path.
+func (s *LocalJobStore) UpsertFile(file FileRecord) error {
+	_, err := s.db.Exec(
+		`INSERT INTO files (id, path, file_type, hash) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET path = excluded.path, file_type = excluded.file_type, hash = excluded.hash`,
+		file.ID, file.Path, file.FileType, file.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert file %s: %w", file.ID, err)
+	}
+	return nil
+}
+
+// RegisterFile satisfies FileRegistrar, minting a local file ID the same
+// way Create mints a job ID, so a pipeline spec run's intermediate stage
+// outputs can be queued as the next stage's input without a Convex
+// document.
+func (s *LocalJobStore) RegisterFile(file FileRecord) (string, error) {
+	if file.ID == "" {
+		file.ID = fmt.Sprintf("file_%d", time.Now().UnixNano())
+	}
+	if err := s.UpsertFile(file); err != nil {
+		return "", err
+	}
+	return file.ID, nil
+}
+
+// GetFileByID satisfies FileLookup, letting processParseJob resolve a
+// file's path and type without Convex once it's been migrated locally.
+func (s *LocalJobStore) GetFileByID(id string) (*FileRecord, error) {
+	row := s.db.QueryRow(`SELECT id, path, file_type, hash FROM files WHERE id = ?`, id)
+
+	var record FileRecord
+	if err := row.Scan(&record.ID, &record.Path, &record.FileType, &record.Hash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("file not found: %s", id)
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *LocalJobStore) Close() error {
+	return s.db.Close()
+}
+
+// jobRowScanner is satisfied by both *sql.Row and *sql.Rows, letting
+// scanJobRow back both GetNextPending's single-row query and ListAll's
+// multi-row iteration.
+type jobRowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJobRow(row jobRowScanner) (*JobRecord, error) {
+	var record JobRecord
+	var metadata string
+
+	err := row.Scan(
+		&record.ID, &record.FileID, &record.ParserID, &record.JobType, &record.Status,
+		&record.Priority, &metadata, &record.StartedAt, &record.CompletedAt, &record.ErrorMessage, &record.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &record.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode job metadata: %w", err)
+		}
+	}
+
+	return &record, nil
+}
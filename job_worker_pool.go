@@ -0,0 +1,140 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// defaultWorkerCount is how many goroutines pull jobs off the dispatch
+// channel concurrently. It bounds total in-flight jobs independent of
+// per-parser MaxConcurrent, which only bounds a single parser's share.
+const defaultWorkerCount = 4
+
+// defaultDispatchBuffer sizes the channel the poller feeds and workers
+// drain; a small buffer lets the poller keep pulling pending jobs ahead of
+// the workers without unbounded growth.
+const defaultDispatchBuffer = 16
+
+// resourceWaitInterval is how often runJob rechecks the resource budget
+// while waiting for enough capacity to free up.
+const resourceWaitInterval = 200 * time.Millisecond
+
+// defaultResourceBudgetMemMB is the total memory the worker pool will
+// schedule against, absent any more specific machine-sizing logic.
+const defaultResourceBudgetMemMB = 8192
+
+// resourceBudget tracks the worker pool's total CPU/GPU/memory capacity and
+// what's currently claimed by in-flight jobs, so the pool can avoid
+// overcommitting the machine even when every parser's own MaxConcurrent
+// would otherwise allow it. acquire/release are the only way callers touch
+// it, so the utilization GetQueueStatus reports always matches what's
+// actually running.
+type resourceBudget struct {
+	mutex    sync.Mutex
+	totalCPU int
+	totalMem int
+	hasGPU   bool
+	usedCPU  int
+	usedMem  int
+	gpuInUse bool
+}
+
+// newResourceBudget sizes CPU capacity off the host and assumes a single
+// GPU slot is available; there's no GPU discovery in this codebase yet, so
+// claim.GPU is an opt-in declaration parsers can use once that exists.
+func newResourceBudget() *resourceBudget {
+	return &resourceBudget{
+		totalCPU: runtime.NumCPU(),
+		totalMem: defaultResourceBudgetMemMB,
+		hasGPU:   true,
+	}
+}
+
+// acquire reserves claim if it fits within the remaining budget, reporting
+// whether it did. Callers that get false should wait and retry rather than
+// proceed.
+func (b *resourceBudget) acquire(claim ResourceClaim) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.usedCPU+claim.CPU > b.totalCPU {
+		return false
+	}
+	if b.usedMem+claim.MemMB > b.totalMem {
+		return false
+	}
+	if claim.GPU && (!b.hasGPU || b.gpuInUse) {
+		return false
+	}
+
+	b.usedCPU += claim.CPU
+	b.usedMem += claim.MemMB
+	if claim.GPU {
+		b.gpuInUse = true
+	}
+	return true
+}
+
+// release gives back a claim previously returned by acquire.
+func (b *resourceBudget) release(claim ResourceClaim) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.usedCPU -= claim.CPU
+	b.usedMem -= claim.MemMB
+	if claim.GPU {
+		b.gpuInUse = false
+	}
+}
+
+// hasGPUCapacity reports whether this node declared a GPU slot at all,
+// used to advertise a "gpu" capability during distributed-mode heartbeats.
+func (b *resourceBudget) hasGPUCapacity() bool {
+	return b.hasGPU
+}
+
+// snapshot returns current utilization for GetQueueStatus.
+func (b *resourceBudget) snapshot() (usedCPU, usedMem int, gpuInUse bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.usedCPU, b.usedMem, b.gpuInUse
+}
+
+// parserSemaphores hands out a buffered channel per parser ID, sized to
+// that parser's MaxConcurrent the first time it's requested, so at most
+// that many of its jobs run at once regardless of how many workers are
+// idle. A parser's slot size is fixed at first use - reconfiguring
+// MaxConcurrent takes effect on the next process restart.
+type parserSemaphores struct {
+	mutex sync.Mutex
+	slots map[string]chan struct{}
+}
+
+func newParserSemaphores() *parserSemaphores {
+	return &parserSemaphores{slots: make(map[string]chan struct{})}
+}
+
+func (p *parserSemaphores) acquire(parserID string, maxConcurrent int) {
+	p.slotFor(parserID, maxConcurrent) <- struct{}{}
+}
+
+func (p *parserSemaphores) release(parserID string, maxConcurrent int) {
+	<-p.slotFor(parserID, maxConcurrent)
+}
+
+func (p *parserSemaphores) slotFor(parserID string, maxConcurrent int) chan struct{} {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	slot, ok := p.slots[parserID]
+	if !ok {
+		slot = make(chan struct{}, maxConcurrent)
+		p.slots[parserID] = slot
+	}
+	return slot
+}
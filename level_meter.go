@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Level metering tuning. Emission is throttled independently of the
+// callback's own buffer rate (which can be 100Hz+) so the frontend isn't
+// flooded, and silence detection uses a coarser RMS floor than the VAD
+// speech threshold since it only needs to catch a dead/muted input.
+const (
+	levelMeterInterval      = 50 * time.Millisecond // ~20Hz
+	levelClipRatio          = 0.999                 // peak/full-scale ratio counted as clipping
+	silenceWarningThreshold = -40.0                 // dBFS; RMS below this counts as silence
+	silenceWarningDelay     = 3 * time.Second        // how long a recording can be silent before warning
+	dbfsFloor               = -120.0                // reported for a zero signal instead of -Inf
+)
+
+// InputLevel is the payload of the inputLevel Wails event, emitted
+// throttled from the PortAudio callback while recording.
+type InputLevel struct {
+	PeakDbfs float64 `json:"peakDbfs"`
+	RmsDbfs  float64 `json:"rmsDbfs"`
+	Clipped  bool    `json:"clipped"`
+}
+
+// levelSample is what the audio callback hands off to the decoupled
+// emitter goroutine; it carries a bit more than InputLevel so the goroutine
+// can also drive the silenceWarning check without touching AudioService
+// state from two goroutines at once.
+type levelSample struct {
+	peakRatio float64
+	rmsRatio  float64
+	clipped   bool
+	hadSound  bool
+	elapsed   time.Duration
+}
+
+// dbfs converts a 0..1 ratio of full scale to decibels relative to full
+// scale, flooring at dbfsFloor instead of returning -Inf for silence.
+func dbfs(ratio float64) float64 {
+	if ratio <= 0 {
+		return dbfsFloor
+	}
+	value := 20 * math.Log10(ratio)
+	if value < dbfsFloor {
+		return dbfsFloor
+	}
+	return value
+}
+
+// peakRMSInt16 computes the peak and RMS amplitude of samples as a ratio
+// of full scale (0..1).
+func peakRMSInt16(samples []int16) (peakRatio float64, rmsRatio float64) {
+	var peakAbs, sumSquares float64
+	for _, sample := range samples {
+		v := float64(sample)
+		if v < 0 {
+			v = -v
+		}
+		if v > peakAbs {
+			peakAbs = v
+		}
+		sumSquares += v * v
+	}
+	rms := math.Sqrt(sumSquares / float64(len(samples)))
+	return peakAbs / 32768.0, rms / 32768.0
+}
+
+// peakRMSInt32 is the 32-bit-capture counterpart of peakRMSInt16.
+func peakRMSInt32(samples []int32) (peakRatio float64, rmsRatio float64) {
+	var peakAbs, sumSquares float64
+	for _, sample := range samples {
+		v := float64(sample)
+		if v < 0 {
+			v = -v
+		}
+		if v > peakAbs {
+			peakAbs = v
+		}
+		sumSquares += v * v
+	}
+	rms := math.Sqrt(sumSquares / float64(len(samples)))
+	return peakAbs / 2147483648.0, rms / 2147483648.0
+}
+
+// recordLevel is called from the PortAudio callback goroutine with the
+// peak/RMS ratio of the buffer just captured. It tracks whether the
+// recording has had any sound yet and, throttled to levelMeterInterval,
+// hands a sample off to the decoupled emitter goroutine via levelChan so
+// the audio thread never blocks on runtime.EventsEmit.
+func (s *AudioService) recordLevel(peakRatio, rmsRatio float64) {
+	if dbfs(rmsRatio) > silenceWarningThreshold {
+		s.recordingHadSound = true
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastLevelEmit) < levelMeterInterval {
+		return
+	}
+	s.lastLevelEmit = now
+
+	sample := levelSample{
+		peakRatio: peakRatio,
+		rmsRatio:  rmsRatio,
+		clipped:   peakRatio >= levelClipRatio,
+		hadSound:  s.recordingHadSound,
+		elapsed:   now.Sub(s.recordingStartTime),
+	}
+
+	select {
+	case s.levelChan <- sample:
+	default:
+		// Emitter goroutine is behind; levels are for live UI feedback
+		// only, so drop rather than block the audio callback.
+	}
+}
+
+// runLevelMeter reads samples off levelChan and emits them as inputLevel
+// events, plus a one-shot silenceWarning if the recording has gone
+// silenceWarningDelay without any sound. It exits when done is closed.
+func (s *AudioService) runLevelMeter(done <-chan struct{}) {
+	silenceWarned := false
+
+	for {
+		select {
+		case <-done:
+			return
+		case sample, ok := <-s.levelChan:
+			if !ok {
+				return
+			}
+
+			runtime.EventsEmit(s.ctx, "inputLevel", InputLevel{
+				PeakDbfs: dbfs(sample.peakRatio),
+				RmsDbfs:  dbfs(sample.rmsRatio),
+				Clipped:  sample.clipped,
+			})
+
+			if !silenceWarned && !sample.hadSound && sample.elapsed > silenceWarningDelay {
+				silenceWarned = true
+				runtime.EventsEmit(s.ctx, "silenceWarning", "No sound detected since recording started - check your input device")
+			}
+		}
+	}
+}
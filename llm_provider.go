@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// LLMProvider abstracts a single text-completion provider, the same way
+// TranscriptionBackend abstracts a transcription provider: LLMSummarizer
+// talks only to this interface, never to a provider's HTTP details, so
+// picking a different provider per parser is a config change instead of a
+// code change.
+type LLMProvider interface {
+	// Complete sends prompt to model and returns the model's full text
+	// response.
+	Complete(ctx context.Context, model, prompt string) (string, error)
+}
+
+// LLMProviderID selects which LLMProvider implementation NewLLMProvider
+// builds, carried in the parser document's "provider" field so a parser can
+// switch providers without a code change.
+type LLMProviderID string
+
+const (
+	LLMProviderOpenAI    LLMProviderID = "openai"
+	LLMProviderAnthropic LLMProviderID = "anthropic"
+	LLMProviderOllama    LLMProviderID = "ollama"
+)
+
+// defaultOllamaBaseURL is used when a parser configured for the "ollama"
+// provider doesn't override "baseURL" - Ollama's default local listen
+// address.
+const defaultOllamaBaseURL = "http://localhost:11434"
+
+// NewLLMProvider builds the LLMProvider selected by config["provider"],
+// defaulting to LLMProviderOpenAI when unset. config is the raw parser
+// document, the same way NewTranscriptionBackend reads its backend
+// selection and credentials straight off the Convex-managed configuration.
+func NewLLMProvider(config map[string]interface{}) (LLMProvider, error) {
+	providerID := LLMProviderOpenAI
+	if raw, ok := config["provider"].(string); ok && raw != "" {
+		providerID = LLMProviderID(raw)
+	}
+
+	apiKey, _ := config["apiKey"].(string)
+
+	switch providerID {
+	case LLMProviderOpenAI:
+		if apiKey == "" {
+			return nil, fmt.Errorf("OpenAI API key not configured")
+		}
+		return &openAILLMProvider{apiKey: apiKey}, nil
+	case LLMProviderAnthropic:
+		if apiKey == "" {
+			return nil, fmt.Errorf("Anthropic API key not configured")
+		}
+		return &anthropicLLMProvider{apiKey: apiKey}, nil
+	case LLMProviderOllama:
+		baseURL, _ := config["baseURL"].(string)
+		if baseURL == "" {
+			baseURL = defaultOllamaBaseURL
+		}
+		return &ollamaLLMProvider{baseURL: baseURL}, nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %q", providerID)
+	}
+}
+
+// openAILLMProvider completes via OpenAI's chat completions endpoint.
+type openAILLMProvider struct {
+	apiKey string
+}
+
+func (p *openAILLMProvider) Complete(ctx context.Context, model, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model": model,
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": prompt},
+		},
+		"temperature": 0.3,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "openai:chat", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(payloadBytes))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no completion choices returned")
+	}
+
+	return result.Choices[0].Message.Content, nil
+}
+
+// anthropicLLMProvider completes via Anthropic's messages endpoint.
+type anthropicLLMProvider struct {
+	apiKey string
+}
+
+func (p *anthropicLLMProvider) Complete(ctx context.Context, model, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model":      model,
+		"max_tokens": 1024,
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": prompt},
+		},
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "anthropic:messages", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(payloadBytes))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("x-api-key", p.apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("no content blocks returned")
+	}
+
+	return result.Content[0].Text, nil
+}
+
+// ollamaLLMProvider completes via a local or self-hosted Ollama server's
+// generate endpoint - no API key, just a reachable baseURL.
+type ollamaLLMProvider struct {
+	baseURL string
+}
+
+func (p *ollamaLLMProvider) Complete(ctx context.Context, model, prompt string) (string, error) {
+	payload := map[string]interface{}{
+		"model":  model,
+		"prompt": prompt,
+		"stream": false,
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "ollama:generate", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewReader(payloadBytes))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Response string `json:"response"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return result.Response, nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var llmSummarizerLog = GetFacility("llmsummarizer")
+
+// defaultSummarizerModel and defaultSummarizerPromptTemplate apply when a
+// "summary" parser's configuration doesn't override them. "{{text}}" in the
+// template is replaced with the text being summarized, whether that's the
+// original input or, on a reduce pass, the concatenation of prior chunk
+// summaries.
+const (
+	defaultSummarizerModel          = "gpt-4o-mini"
+	defaultSummarizerPromptTemplate = "Summarize the following text concisely, capturing the key points and main ideas:\n\n{{text}}"
+)
+
+// defaultMaxInputTokens and defaultChunkOverlapTokens bound the map-reduce
+// window when a parser's configuration doesn't set maxInputTokens/
+// chunkOverlapTokens: a conservative window well under typical context
+// limits, with enough overlap that a sentence split across chunk
+// boundaries still appears whole in at least one of them.
+const (
+	defaultMaxInputTokens     = 3000
+	defaultChunkOverlapTokens = 200
+)
+
+// tokensPerWord approximates the tokens-per-word ratio of English text for
+// providers that don't expose a tokenizer locally (estimateTokenCount is
+// deliberately the same kind of rough, dependency-free approximation
+// estimateDurationSecondsByFileSize uses for audio duration).
+const tokensPerWord = 1.3
+
+// llmSummarizerConfig is the subset of a "summary" parser's configuration
+// SummaryParserImpl.Process reads, with defaults applied.
+type llmSummarizerConfig struct {
+	model              string
+	promptTemplate     string
+	maxInputTokens     int
+	chunkOverlapTokens int
+}
+
+func loadLLMSummarizerConfig(configuration map[string]interface{}) llmSummarizerConfig {
+	cfg := llmSummarizerConfig{
+		model:              defaultSummarizerModel,
+		promptTemplate:     defaultSummarizerPromptTemplate,
+		maxInputTokens:     defaultMaxInputTokens,
+		chunkOverlapTokens: defaultChunkOverlapTokens,
+	}
+
+	if model, ok := configuration["model"].(string); ok && model != "" {
+		cfg.model = model
+	}
+	if tmpl, ok := configuration["promptTemplate"].(string); ok && tmpl != "" {
+		cfg.promptTemplate = tmpl
+	}
+	if max, ok := configuration["maxInputTokens"].(float64); ok && max > 0 {
+		cfg.maxInputTokens = int(max)
+	}
+	if overlap, ok := configuration["chunkOverlapTokens"].(float64); ok && overlap >= 0 {
+		cfg.chunkOverlapTokens = int(overlap)
+	}
+
+	return cfg
+}
+
+// estimateTokenCount approximates how many tokens text costs a provider,
+// close enough to gate map-reduce chunking without a real tokenizer.
+func estimateTokenCount(text string) int {
+	words := len(strings.Fields(text))
+	return int(float64(words) * tokensPerWord)
+}
+
+// applyPromptTemplate substitutes text into template's "{{text}}"
+// placeholder, or appends text if the template doesn't contain one.
+func applyPromptTemplate(template, text string) string {
+	if strings.Contains(template, "{{text}}") {
+		return strings.ReplaceAll(template, "{{text}}", text)
+	}
+	return template + "\n\n" + text
+}
+
+// summarizeMapReduce summarizes text in one completion call if it fits
+// within cfg.maxInputTokens, or otherwise splits it into overlapping
+// token-window chunks, summarizes each (skipping any already summarized in
+// a prior run, per cache), and recursively summarizes the concatenation of
+// those chunk summaries until the result fits the window.
+func summarizeMapReduce(ctx context.Context, provider LLMProvider, cfg llmSummarizerConfig, text string, cache *chunkSummaryCache) (string, error) {
+	if estimateTokenCount(text) <= cfg.maxInputTokens {
+		return provider.Complete(ctx, cfg.model, applyPromptTemplate(cfg.promptTemplate, text))
+	}
+
+	chunks := splitIntoTokenWindows(text, cfg.maxInputTokens, cfg.chunkOverlapTokens)
+	chunkSummaries := make([]string, len(chunks))
+
+	for i, chunk := range chunks {
+		summary, err := cache.getOrCompute(chunk, func() (string, error) {
+			return provider.Complete(ctx, cfg.model, applyPromptTemplate(cfg.promptTemplate, chunk))
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		chunkSummaries[i] = summary
+	}
+
+	llmSummarizerLog.Debugf("map-reduce: summarized %d chunks, reducing\n", len(chunks))
+	return summarizeMapReduce(ctx, provider, cfg, strings.Join(chunkSummaries, "\n\n"), cache)
+}
+
+// splitIntoTokenWindows splits text into word-bounded chunks approximating
+// maxTokens each, with the last overlapTokens worth of words from one chunk
+// repeated at the start of the next, so a sentence spanning a chunk
+// boundary still appears whole in at least one chunk.
+func splitIntoTokenWindows(text string, maxTokens, overlapTokens int) []string {
+	words := strings.Fields(text)
+
+	windowWords := int(float64(maxTokens) / tokensPerWord)
+	if windowWords < 1 {
+		windowWords = 1
+	}
+	overlapWords := int(float64(overlapTokens) / tokensPerWord)
+	if overlapWords >= windowWords {
+		overlapWords = windowWords - 1
+	}
+	if overlapWords < 0 {
+		overlapWords = 0
+	}
+
+	var chunks []string
+	for start := 0; start < len(words); {
+		end := start + windowWords
+		if end > len(words) {
+			end = len(words)
+		}
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+		start = end - overlapWords
+	}
+
+	return chunks
+}
+
+// chunkSummaryRecord is one line of a "<base>_summary.chunks.jsonl" file:
+// a chunk's content hash plus the summary the LLM produced for it.
+type chunkSummaryRecord struct {
+	ChunkHash string `json:"chunkHash"`
+	Summary   string `json:"summary"`
+}
+
+// chunkSummaryCache is the on-disk memo of per-chunk summaries backing
+// SummaryParserImpl's map-reduce pass: keying on the chunk's own content
+// hash (rather than the input file's hash) means a re-run that only
+// slightly changed one chunk still reuses every other chunk's summary.
+type chunkSummaryCache struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]string
+}
+
+// loadChunkSummaryCache reads path's existing chunk summaries, if any, so
+// SummaryParserImpl.Process can resume a previously interrupted or
+// partially-matching map-reduce pass instead of re-summarizing every chunk.
+func loadChunkSummaryCache(path string) (*chunkSummaryCache, error) {
+	cache := &chunkSummaryCache{path: path, entries: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var record chunkSummaryRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			llmSummarizerLog.Debugf("skipping malformed chunk summary record in %s: %v\n", path, err)
+			continue
+		}
+		cache.entries[record.ChunkHash] = record.Summary
+	}
+
+	return cache, nil
+}
+
+// getOrCompute returns the cached summary for chunk's content hash, or
+// calls compute, appends the result to the cache's backing file, and
+// returns it.
+func (c *chunkSummaryCache) getOrCompute(chunk string, compute func() (string, error)) (string, error) {
+	hash := hashBytes([]byte(chunk))
+
+	c.mutex.Lock()
+	if summary, ok := c.entries[hash]; ok {
+		c.mutex.Unlock()
+		return summary, nil
+	}
+	c.mutex.Unlock()
+
+	summary, err := compute()
+	if err != nil {
+		return "", err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[hash] = summary
+
+	encoded, err := json.Marshal(chunkSummaryRecord{ChunkHash: hash, Summary: summary})
+	if err != nil {
+		return summary, fmt.Errorf("failed to marshal chunk summary record: %w", err)
+	}
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return summary, fmt.Errorf("failed to open chunk summary cache %s: %w", c.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(encoded, '\n')); err != nil {
+		return summary, fmt.Errorf("failed to append chunk summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+// generateSimpleSummary creates a basic heuristic summary of text: some
+// document statistics plus its opening, middle, and closing sentences. It
+// backs SummaryParserImpl.Process's offline fallback when no LLM provider
+// is configured, and was the "summary" parser's only implementation before
+// this file existed.
+func generateSimpleSummary(text string) string {
+	sentences := strings.Split(text, ".")
+	cleanSentences := make([]string, 0)
+
+	for _, sentence := range sentences {
+		sentence = strings.TrimSpace(sentence)
+		if len(sentence) > 10 { // Only keep substantial sentences
+			cleanSentences = append(cleanSentences, sentence)
+		}
+	}
+
+	if len(cleanSentences) == 0 {
+		return "Summary: [No substantial content found]"
+	}
+
+	words := strings.Fields(text)
+	wordCount := len(words)
+	sentenceCount := len(cleanSentences)
+
+	summary := fmt.Sprintf("SUMMARY\n\n")
+	summary += fmt.Sprintf("Document Statistics:\n")
+	summary += fmt.Sprintf("- Word count: %d\n", wordCount)
+	summary += fmt.Sprintf("- Sentence count: %d\n", sentenceCount)
+	summary += fmt.Sprintf("- Estimated reading time: %.1f minutes\n\n", float64(wordCount)/200)
+
+	if len(cleanSentences) >= 2 {
+		summary += fmt.Sprintf("Key Points:\n")
+		summary += fmt.Sprintf("- Opening: %s.\n", cleanSentences[0])
+		if len(cleanSentences) > 2 {
+			summary += fmt.Sprintf("- Middle: %s.\n", cleanSentences[len(cleanSentences)/2])
+		}
+		summary += fmt.Sprintf("- Closing: %s.\n", cleanSentences[len(cleanSentences)-1])
+	} else {
+		summary += fmt.Sprintf("Content: %s.\n", cleanSentences[0])
+	}
+
+	return summary
+}
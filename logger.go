@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// logRingBufferSize bounds how many recent log entries GetRecentLogs can
+// return, so a long-running session doesn't grow this without bound.
+const logRingBufferSize = 1000
+
+// LogEntry is a single line captured by a Facility, as returned to the
+// frontend's Debug panel.
+type LogEntry struct {
+	TimestampMs int64  `json:"timestampMs"`
+	Facility    string `json:"facility"`
+	Message     string `json:"message"`
+}
+
+// Facility is a named debug channel, modeled on syncthing's facility
+// logger: packages obtain one with GetFacility(name) and call Debugln /
+// Debugf, which are near-no-ops unless that facility has been enabled.
+// This keeps debug logging cheap enough to leave in hot paths like the
+// PortAudio callback.
+type Facility struct {
+	name    string
+	enabled bool
+}
+
+// ShouldDebug reports whether this facility's debug logging is enabled.
+// It's exported so hot paths can skip argument construction entirely
+// when debugging is off: `if f.ShouldDebug() { f.Debugf(...) }`.
+func (f *Facility) ShouldDebug() bool {
+	return facilityRegistry.isEnabled(f.name)
+}
+
+// Debugln logs args if this facility is enabled, in the style of fmt.Println.
+func (f *Facility) Debugln(args ...interface{}) {
+	if !f.ShouldDebug() {
+		return
+	}
+	facilityRegistry.record(f.name, fmt.Sprintln(args...))
+}
+
+// Debugf logs a formatted message if this facility is enabled, in the
+// style of fmt.Printf.
+func (f *Facility) Debugf(format string, args ...interface{}) {
+	if !f.ShouldDebug() {
+		return
+	}
+	facilityRegistry.record(f.name, fmt.Sprintf(format, args...))
+}
+
+// facilityRegistryT tracks every known facility's enabled state and an
+// in-memory ring buffer of recent log entries across all facilities.
+type facilityRegistryT struct {
+	mutex      sync.Mutex
+	facilities map[string]bool
+	entries    []LogEntry
+	nextIdx    int
+}
+
+var facilityRegistry = &facilityRegistryT{
+	facilities: make(map[string]bool),
+}
+
+// GetFacility returns the Facility for name, registering it if this is the
+// first time it's been requested. Facilities start disabled.
+func GetFacility(name string) *Facility {
+	facilityRegistry.mutex.Lock()
+	if _, exists := facilityRegistry.facilities[name]; !exists {
+		facilityRegistry.facilities[name] = false
+	}
+	facilityRegistry.mutex.Unlock()
+	return &Facility{name: name}
+}
+
+func (r *facilityRegistryT) isEnabled(name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.facilities[name]
+}
+
+func (r *facilityRegistryT) record(facility, message string) {
+	entry := LogEntry{
+		TimestampMs: time.Now().UnixMilli(),
+		Facility:    facility,
+		Message:     message,
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.entries) < logRingBufferSize {
+		r.entries = append(r.entries, entry)
+		return
+	}
+
+	r.entries[r.nextIdx] = entry
+	r.nextIdx = (r.nextIdx + 1) % logRingBufferSize
+}
+
+// LoggerService exposes the facility registry to the frontend so a Debug
+// panel can list facilities, toggle them on and off, and stream recent
+// logs while reproducing an issue.
+type LoggerService struct{}
+
+// NewLoggerService creates a new LoggerService.
+func NewLoggerService() *LoggerService {
+	return &LoggerService{}
+}
+
+// ListLogFacilities returns the names of every registered facility.
+func (l *LoggerService) ListLogFacilities() []string {
+	facilityRegistry.mutex.Lock()
+	defer facilityRegistry.mutex.Unlock()
+
+	names := make([]string, 0, len(facilityRegistry.facilities))
+	for name := range facilityRegistry.facilities {
+		names = append(names, name)
+	}
+	return names
+}
+
+// SetFacilityDebug enables or disables debug logging for a facility.
+func (l *LoggerService) SetFacilityDebug(name string, enabled bool) error {
+	facilityRegistry.mutex.Lock()
+	defer facilityRegistry.mutex.Unlock()
+
+	if _, exists := facilityRegistry.facilities[name]; !exists {
+		return fmt.Errorf("unknown log facility: %s", name)
+	}
+
+	facilityRegistry.facilities[name] = enabled
+	return nil
+}
+
+// GetRecentLogs returns buffered log entries newer than sinceUnixMs, in
+// chronological order. Pass 0 to get the full ring buffer.
+func (l *LoggerService) GetRecentLogs(sinceUnixMs int64) []LogEntry {
+	facilityRegistry.mutex.Lock()
+	defer facilityRegistry.mutex.Unlock()
+
+	// entries is append-only until it wraps, at which point nextIdx marks
+	// the oldest entry; walk starting there so results come back in order.
+	ordered := make([]LogEntry, 0, len(facilityRegistry.entries))
+	if len(facilityRegistry.entries) < logRingBufferSize {
+		ordered = append(ordered, facilityRegistry.entries...)
+	} else {
+		ordered = append(ordered, facilityRegistry.entries[facilityRegistry.nextIdx:]...)
+		ordered = append(ordered, facilityRegistry.entries[:facilityRegistry.nextIdx]...)
+	}
+
+	result := make([]LogEntry, 0, len(ordered))
+	for _, entry := range ordered {
+		if entry.TimestampMs > sinceUnixMs {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -1,9 +1,21 @@
+// This is the process entry point for the shipped binary: it wires up
+// CleanApp's live file-processing pipeline (FileWatcherServiceImpl,
+// JobQueueServiceImpl, ParserManagerServiceImpl) alongside the legacy
+// recording App from app.go. Not every request in requests.jsonl landed
+// here - chunk1-1, chunk1-2, chunk1-3, chunk1-5, chunk1-7, chunk5-1,
+// chunk5-2, chunk5-3, and chunk5-4 were all built against App's older
+// FolderMonitorService/ParserService stack, which NewApp never actually
+// constructed or started. That stack was dead code from the moment each
+// of those requests landed and has since been deleted outright; treat
+// all nine as unimplemented rather than delivered, not as work this
+// binary runs.
 package main
 
 import (
 	"context"
 	"embed"
 	"log"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -14,11 +26,37 @@ import (
 var assets embed.FS
 
 func main() {
-	// Create the clean app instance
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		runPipelineSpecCLI(os.Args[2:])
+		return
+	}
+
+	// Create the clean app instance - the file-processing pipeline
+	// (folder watching, parsers, job queue).
 	app := NewCleanApp()
 
+	// Create the legacy recording app instance alongside it - manual
+	// start/stop recording, hotkey/MIDI triggers, and the session audit
+	// log, none of which the clean pipeline has an equivalent for. The two
+	// are bound as separate Wails objects, so the frontend calls
+	// window.go.main.App.* for recording and window.go.main.CleanApp.* for
+	// file processing.
+	fileService := NewFileService()
+	transcriptionService, err := NewTranscriptionService()
+	if err != nil {
+		log.Fatalf("failed to create transcription service: %v", err)
+	}
+	costTrackingService := NewCostTrackingService(nil)
+	auditService := NewAuditService()
+	audioService := NewAudioService(transcriptionService, fileService, costTrackingService, auditService)
+	playbackService := NewPlaybackService()
+	loggerService := NewLoggerService()
+
+	recorder := NewApp(audioService, transcriptionService, fileService, costTrackingService)
+	recorder.auditService = auditService
+
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "Voice Worker - File Processing Pipeline",
 		Width:  1200,
 		Height: 800,
@@ -28,12 +66,26 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup: func(ctx context.Context) {
 			app.startup(ctx)
+			audioService.Initialize()
+			playbackService.SetContext(ctx)
+			recorder.startup(ctx)
 		},
 		OnShutdown: func(ctx context.Context) {
 			app.shutdown(ctx)
+			audioService.Teardown(ctx)
+			playbackService.StopPlayback()
+			recorder.hotkeyService.Stop()
+			if err := costTrackingService.Close(); err != nil {
+				log.Printf("Warning: failed to close legacy cost store: %v", err)
+			}
 		},
 		Bind: []interface{}{
 			app,
+			recorder,
+			audioService,
+			playbackService,
+			costTrackingService,
+			loggerService,
 		},
 	})
 
@@ -41,3 +93,25 @@ func main() {
 		log.Printf("Error starting Voice Worker: %v", err)
 	}
 }
+
+// runPipelineSpecCLI implements `voice-worker run <spec.yaml>`: it wires up
+// a CleanApp the same way the GUI's OnStartup does, submits the spec's
+// first stage, and exits - downstream stages keep queuing in the
+// background via the job queue's pipeline advancer regardless of whether
+// this process is still running.
+func runPipelineSpecCLI(args []string) {
+	if len(args) != 1 {
+		log.Fatalf("usage: voice-worker run <spec.yaml>")
+	}
+
+	app := NewCleanApp()
+	app.startup(context.Background())
+	defer app.shutdown(context.Background())
+
+	queued, err := app.SubmitSpec(args[0])
+	if err != nil {
+		log.Fatalf("failed to submit pipeline spec: %v", err)
+	}
+
+	log.Printf("Queued %d job(s) from pipeline spec %s", queued, args[0])
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var metricsLog = GetFacility("metrics")
+
+// Prometheus collectors shared across the file-hashing and HTTP/Convex
+// call paths, mirroring the kind of counters/histograms/gauges Syncthing
+// exposes for its scanner and model.
+var (
+	hashBytesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "hash_bytes_total",
+		Help: "Total number of bytes read while hashing files.",
+	})
+	convexCallErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "convex_call_errors_total",
+		Help: "Total number of failed Convex API calls, by operation.",
+	}, []string{"op"})
+	httpRetryAttemptsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_retry_attempts_total",
+		Help: "Total number of retried HTTP requests made via httpxDoWithRetry, by endpoint.",
+	}, []string{"endpoint"})
+	circuitBreakerOpenedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_opened_total",
+		Help: "Total number of times a CircuitBreaker tripped from closed/half-open to open, by endpoint.",
+	}, []string{"endpoint"})
+
+	hashDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "hash_duration_seconds",
+		Help: "Time taken to compute a file's SHA-256 hash.",
+	})
+	convexCallDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "convex_call_duration_seconds",
+		Help: "Time taken by Convex API calls, by operation.",
+	}, []string{"op"})
+)
+
+// The voiceworker_-prefixed collectors below are newer additions, namespaced
+// so they read unambiguously on a shared Grafana dashboard alongside metrics
+// from other services. They cover FileWatcherServiceImpl and
+// JobQueueServiceImpl, which predate this file's original collectors above
+// and weren't instrumented at the time.
+var (
+	filesDiscoveredTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "voiceworker_files_discovered_total",
+		Help: "Total number of files discovered by the file watcher (initial scan or fsnotify), by extension.",
+	}, []string{"ext"})
+	jobsByState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "voiceworker_jobs",
+		Help: "Current number of jobs in the queue, by state.",
+	}, []string{"state"})
+	parserProcessSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "voiceworker_parser_process_seconds",
+		Help: "Time taken by a parser's Process call, by parser.",
+	}, []string{"parser"})
+	transcriptionCostDollarsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "voiceworker_transcription_cost_dollars_total",
+		Help: "Total estimated cost in dollars of audio transcribed via the transcription parser.",
+	})
+	transcriptionSecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "voiceworker_transcription_seconds_total",
+		Help: "Total estimated duration in seconds of audio transcribed via the transcription parser.",
+	})
+	jobsDedupedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "voiceworker_jobs_deduped_total",
+		Help: "Total number of parse jobs skipped by reusing a prior completed job's output for the same content hash, by parser.",
+	}, []string{"parser"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		hashBytesTotal,
+		convexCallErrorsTotal,
+		httpRetryAttemptsTotal,
+		circuitBreakerOpenedTotal,
+		hashDurationSeconds,
+		convexCallDurationSeconds,
+		filesDiscoveredTotal,
+		jobsByState,
+		parserProcessSeconds,
+		transcriptionCostDollarsTotal,
+		transcriptionSecondsTotal,
+		jobsDedupedTotal,
+	)
+}
+
+// MetricsService exposes the collectors above over a /metrics endpoint for
+// Prometheus to scrape, the same way the rest of the app exposes state to
+// the Wails frontend - except this is for operators, not the UI.
+type MetricsService struct {
+	server *http.Server
+}
+
+// NewMetricsService creates a MetricsService listening on METRICS_ADDR (or,
+// if that's unset, 127.0.0.1:METRICS_PORT), or nil if neither is set - the
+// metrics endpoint is opt-in, since most
+// local/desktop runs of Voice Worker have no Prometheus scraping it. Set
+// METRICS_ADDR to a bare port (resolved against 127.0.0.1, following
+// METRICS_PORT's old behavior) or a full host:port.
+func NewMetricsService() *MetricsService {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		if port := os.Getenv("METRICS_PORT"); port != "" {
+			addr = fmt.Sprintf("127.0.0.1:%s", port)
+		} else {
+			return nil
+		}
+	} else if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = fmt.Sprintf("127.0.0.1:%s", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &MetricsService{
+		server: &http.Server{
+			Addr:    addr,
+			Handler: mux,
+		},
+	}
+}
+
+// Start begins serving /metrics in the background. A nil MetricsService (the
+// metrics endpoint wasn't opted into) is a no-op.
+func (m *MetricsService) Start() {
+	if m == nil {
+		return
+	}
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			metricsLog.Debugf("Error serving metrics: %v\n", err)
+		}
+	}()
+	metricsLog.Debugf("Metrics endpoint listening on %s/metrics\n", m.server.Addr)
+}
+
+// Stop gracefully shuts down the metrics HTTP server. A nil MetricsService is
+// a no-op.
+func (m *MetricsService) Stop(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	if err := m.server.Shutdown(ctx); err != nil {
+		metricsLog.Debugf("Error shutting down metrics server: %v\n", err)
+	}
+}
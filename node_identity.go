@@ -0,0 +1,51 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// nodeIDFile persists this machine's distributed-mode node ID, the same
+// way jobStoreConfigFile persists the job store backend choice, so it
+// survives restarts instead of reapStale treating every restart as a new
+// node.
+const nodeIDFile = "voiceworker_node_id"
+
+// loadOrCreateNodeID returns the node ID persisted at nodeIDFile, minting
+// and saving one on first run.
+func loadOrCreateNodeID() (string, error) {
+	if data, err := os.ReadFile(nodeIDFile); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	}
+
+	id, err := newNodeID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate node id: %w", err)
+	}
+
+	if err := os.WriteFile(nodeIDFile, []byte(id), 0644); err != nil {
+		return "", fmt.Errorf("failed to persist node id: %w", err)
+	}
+	return id, nil
+}
+
+// newNodeID combines the host's name with a random suffix so two nodes
+// sharing a hostname (containers, VM clones) still get distinct IDs.
+func newNodeID() (string, error) {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "node"
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%s", hostname, hex.EncodeToString(suffix)), nil
+}
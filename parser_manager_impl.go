@@ -1,19 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 )
 
+// defaultPluginsDir is where loadExternalPlugins looks for out-of-process
+// parser executables, mirroring defaultLocalJobStorePath's convention of a
+// plain relative path next to the binary.
+const defaultPluginsDir = "plugins"
+
 type ParserManagerServiceImpl struct {
-	convexClient *ConvexClient
-	parsers      map[string]Parser
+	convexClient   *ConvexClient
+	parsers        map[string]Parser
+	pluginRegistry *PluginRegistry
 }
 
 func NewParserManagerServiceImpl(convexClient *ConvexClient) *ParserManagerServiceImpl {
 	pm := &ParserManagerServiceImpl{
-		convexClient: convexClient,
-		parsers:      make(map[string]Parser),
+		convexClient:   convexClient,
+		parsers:        make(map[string]Parser),
+		pluginRegistry: NewPluginRegistry(defaultPluginsDir),
 	}
 
 	// Initialize built-in parsers
@@ -30,6 +38,7 @@ func (p *ParserManagerServiceImpl) initializeBuiltInParsers() {
 		inputTypes:      []string{"audio"},
 		outputExtension: ".txt",
 		outputSuffix:    "",
+		convexClient:    p.convexClient,
 	}
 
 	// Create summary parser
@@ -44,10 +53,29 @@ func (p *ParserManagerServiceImpl) initializeBuiltInParsers() {
 	p.parsers["transcription"] = transcriptionParser
 	p.parsers["summary"] = summaryParser
 
+	p.loadExternalPlugins()
+
 	// Ensure parsers exist in Convex
 	p.ensureParsersInConvex()
 }
 
+// loadExternalPlugins starts pluginRegistry (scanning defaultPluginsDir
+// for plugin executables, spawning and handshaking with each) and
+// registers every one it finds into p.parsers alongside the built-ins. A
+// directory that doesn't exist yet just yields no plugins, and a plugin
+// that fails to start is logged by the registry; neither is treated as an
+// error - plugins are opt-in, not required for the app to run.
+func (p *ParserManagerServiceImpl) loadExternalPlugins() {
+	if err := p.pluginRegistry.Start(context.Background()); err != nil {
+		log.Printf("Warning: failed to start plugin registry: %v", err)
+		return
+	}
+
+	for _, plugin := range p.pluginRegistry.Parsers() {
+		p.parsers[plugin.GetID()] = plugin
+	}
+}
+
 func (p *ParserManagerServiceImpl) ensureParsersInConvex() {
 	for _, parser := range p.parsers {
 		_, err := p.convexClient.CallMutation("parsers:createOrUpdate", map[string]interface{}{
@@ -1,17 +1,11 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
 // TranscriptionParserImpl implements the Parser interface for audio transcription
@@ -22,6 +16,12 @@ type TranscriptionParserImpl struct {
 	outputExtension string
 	outputSuffix    string
 	configuration   map[string]interface{}
+
+	// convexClient is threaded into NewTranscriptionBackend so its cost
+	// estimate can reuse probeAudioFileCached's hash-keyed cache instead of
+	// re-probing the file on every EstimateCost call (e.g. every budget-gate
+	// check). May be nil, in which case every estimate re-probes.
+	convexClient *ConvexClient
 }
 
 func (t *TranscriptionParserImpl) GetID() string {
@@ -59,105 +59,50 @@ func (t *TranscriptionParserImpl) CanProcess(filePath, fileType string) bool {
 	return supportedExts[ext]
 }
 
+// Process dispatches to the TranscriptionBackend named by
+// configuration["backend"] (OpenAI Whisper if unset) - TranscriptionParserImpl
+// itself no longer knows anything about a specific provider's HTTP or CLI
+// details.
 func (t *TranscriptionParserImpl) Process(ctx context.Context, inputPath, outputPath string) error {
-	// Get API key from configuration
-	apiKey, ok := t.configuration["apiKey"].(string)
-	if !ok || apiKey == "" {
-		return fmt.Errorf("OpenAI API key not configured")
-	}
-
-	// Open the audio file
-	file, err := os.Open(inputPath)
+	backend, err := NewTranscriptionBackend(t.configuration, t.convexClient)
 	if err != nil {
-		return fmt.Errorf("failed to open audio file: %v", err)
+		appLog.Error(ctx, "failed to construct transcription backend", F("parser_id", t.id), F("file_path", inputPath), F("error", err.Error()))
+		return err
 	}
-	defer file.Close()
 
-	// Create multipart form
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	// Add file field
-	fileWriter, err := writer.CreateFormFile("file", filepath.Base(inputPath))
+	transcript, err := transcribeWithChunking(ctx, backend, inputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create form file: %v", err)
-	}
-
-	_, err = io.Copy(fileWriter, file)
-	if err != nil {
-		return fmt.Errorf("failed to copy file data: %v", err)
-	}
-
-	// Add model field
-	model := "whisper-1"
-	if configModel, ok := t.configuration["model"].(string); ok && configModel != "" {
-		model = configModel
+		appLog.Error(ctx, "transcription failed", F("parser_id", t.id), F("file_path", inputPath), F("error", err.Error()))
+		return err
 	}
 
-	err = writer.WriteField("model", model)
-	if err != nil {
-		return fmt.Errorf("failed to write model field: %v", err)
-	}
-
-	// Add response format
-	err = writer.WriteField("response_format", "text")
-	if err != nil {
-		return fmt.Errorf("failed to write response format field: %v", err)
-	}
-
-	writer.Close()
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/audio/transcriptions", &requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// Send request
-	client := &http.Client{Timeout: 30 * time.Minute}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Read response
-	transcription, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response: %v", err)
+	if err := os.WriteFile(outputPath, []byte(transcript.Text), 0644); err != nil {
+		appLog.Error(ctx, "failed to write transcript output", F("parser_id", t.id), F("file_path", outputPath), F("error", err.Error()))
+		return fmt.Errorf("failed to write output file: %v", err)
 	}
 
-	// Write transcription to output file
-	err = os.WriteFile(outputPath, transcription, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write output file: %v", err)
+	// Record the same cost estimate EstimateCost reports, so
+	// voiceworker_transcription_cost_dollars_total tracks what operators
+	// were quoted rather than drifting from it.
+	if estimatedSeconds, err := estimateDurationSecondsByFileSize(t.convexClient, inputPath); err == nil {
+		transcriptionSecondsTotal.Add(estimatedSeconds)
+		if cost, err := backend.EstimateCost(inputPath); err == nil {
+			transcriptionCostDollarsTotal.Add(cost)
+		}
 	}
 
 	return nil
 }
 
+// EstimateCost defers to the configured backend's own cost model - a local
+// whisper.cpp backend reports 0, while a hosted provider reports its
+// per-minute rate against the same file-size heuristic.
 func (t *TranscriptionParserImpl) EstimateCost(filePath string) (float64, error) {
-	// Get file info
-	info, err := os.Stat(filePath)
+	backend, err := NewTranscriptionBackend(t.configuration, t.convexClient)
 	if err != nil {
 		return 0, err
 	}
-
-	// Estimate based on file size (rough approximation)
-	// Assume 1MB per minute of audio, $0.006 per minute
-	fileSizeMB := float64(info.Size()) / (1024 * 1024)
-	estimatedMinutes := fileSizeMB // rough approximation
-	cost := estimatedMinutes * 0.006 // OpenAI Whisper pricing
-
-	return cost, nil
+	return backend.EstimateCost(filePath)
 }
 
 func (t *TranscriptionParserImpl) GetConfiguration() map[string]interface{} {
@@ -172,6 +117,36 @@ func (t *TranscriptionParserImpl) SetConfiguration(config map[string]interface{}
 	return nil
 }
 
+// GetMaxConcurrent defaults to 1: the Whisper API rate-limits aggressively
+// per key, so running more than one transcription at a time mostly just
+// trades queued jobs for 429s. Override via configuration["maxConcurrent"].
+func (t *TranscriptionParserImpl) GetMaxConcurrent() int {
+	if n, ok := t.configuration["maxConcurrent"].(float64); ok && n > 0 {
+		return int(n)
+	}
+	return 1
+}
+
+// GetResourceClaim is the same for every backend. With a hosted backend
+// it's mostly nominal - transcription is a network-bound call, not local
+// compute - and the whisper.cpp backend, which does run locally, makes the
+// same claim. Either way it claims a CPU slot so it counts against the
+// worker pool's budget alongside CPU-bound parsers.
+func (t *TranscriptionParserImpl) GetResourceClaim() ResourceClaim {
+	return ResourceClaim{CPU: 1, GPU: false, MemMB: 256}
+}
+
+// GetSchedulerWeight defaults to 1 - transcriptions run long enough that
+// giving them a bigger dispatch share wouldn't help throughput, and a
+// smaller one is exactly what keeps them from starving a deep backlog of
+// fast summaries. Override via configuration["schedulerWeight"].
+func (t *TranscriptionParserImpl) GetSchedulerWeight() int {
+	if n, ok := t.configuration["schedulerWeight"].(float64); ok && n > 0 {
+		return int(n)
+	}
+	return 1
+}
+
 // SummaryParserImpl implements the Parser interface for text summarization
 type SummaryParserImpl struct {
 	id              string
@@ -220,97 +195,51 @@ func (s *SummaryParserImpl) CanProcess(filePath, fileType string) bool {
 	return true
 }
 
+// Process summarizes inputPath via the LLM provider named by
+// configuration["provider"] (OpenAI by default, see NewLLMProvider),
+// map-reducing through summarizeMapReduce when the input is too large for
+// one completion call. If no provider is configured (no apiKey set and the
+// default provider requires one), it falls back to generateSimpleSummary's
+// offline heuristic rather than failing the job outright.
 func (s *SummaryParserImpl) Process(ctx context.Context, inputPath, outputPath string) error {
-	// Get API key from configuration
-	apiKey, ok := s.configuration["apiKey"].(string)
-	if !ok || apiKey == "" {
-		return fmt.Errorf("OpenAI API key not configured")
-	}
-
-	// Read input text
 	textContent, err := os.ReadFile(inputPath)
 	if err != nil {
+		appLog.Error(ctx, "failed to read input file", F("parser_id", s.id), F("file_path", inputPath), F("error", err.Error()))
 		return fmt.Errorf("failed to read input file: %v", err)
 	}
 
-	text := string(textContent)
-	if len(text) == 0 {
+	text := strings.TrimSpace(string(textContent))
+	if text == "" {
+		appLog.Error(ctx, "input file is empty", F("parser_id", s.id), F("file_path", inputPath))
 		return fmt.Errorf("input file is empty")
 	}
 
-	// Get model from configuration
-	model := "gpt-3.5-turbo"
-	if configModel, ok := s.configuration["model"].(string); ok && configModel != "" {
-		model = configModel
-	}
-
-	// Create request payload
-	payload := map[string]interface{}{
-		"model": model,
-		"messages": []map[string]interface{}{
-			{
-				"role": "system",
-				"content": "You are a helpful assistant that creates concise summaries of text content. Provide a clear, informative summary that captures the key points and main ideas.",
-			},
-			{
-				"role": "user",
-				"content": fmt.Sprintf("Please summarize this text:\n\n%s", text),
-			},
-		},
-		"max_tokens": 500,
-		"temperature": 0.3,
-	}
-
-	payloadBytes, err := json.Marshal(payload)
+	provider, err := NewLLMProvider(s.configuration)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request payload: %v", err)
+		appLog.Warn(ctx, "no LLM provider configured, falling back to heuristic summary", F("parser_id", s.id), F("file_path", inputPath), F("error", err.Error()))
+		if err := os.WriteFile(outputPath, []byte(generateSimpleSummary(text)), 0644); err != nil {
+			appLog.Error(ctx, "failed to write summary output", F("parser_id", s.id), F("file_path", outputPath), F("error", err.Error()))
+			return fmt.Errorf("failed to write output file: %v", err)
+		}
+		return nil
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Do(req)
+	cfg := loadLLMSummarizerConfig(s.configuration)
+	chunksPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".chunks.jsonl"
+	cache, err := loadChunkSummaryCache(chunksPath)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
+		appLog.Error(ctx, "failed to load chunk summary cache", F("parser_id", s.id), F("file_path", inputPath), F("error", err.Error()))
+		return fmt.Errorf("failed to load chunk summary cache: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Parse response
-	var response struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(&response)
+	summary, err := summarizeMapReduce(ctx, provider, cfg, text, cache)
 	if err != nil {
-		return fmt.Errorf("failed to decode response: %v", err)
+		appLog.Error(ctx, "summarization failed", F("parser_id", s.id), F("file_path", inputPath), F("error", err.Error()))
+		return fmt.Errorf("summarization failed: %w", err)
 	}
 
-	if len(response.Choices) == 0 {
-		return fmt.Errorf("no summary generated")
-	}
-
-	summary := response.Choices[0].Message.Content
-
-	// Write summary to output file
-	err = os.WriteFile(outputPath, []byte(summary), 0644)
-	if err != nil {
+	if err := os.WriteFile(outputPath, []byte(summary), 0644); err != nil {
+		appLog.Error(ctx, "failed to write summary output", F("parser_id", s.id), F("file_path", outputPath), F("error", err.Error()))
 		return fmt.Errorf("failed to write output file: %v", err)
 	}
 
@@ -345,3 +274,31 @@ func (s *SummaryParserImpl) SetConfiguration(config map[string]interface{}) erro
 	s.configuration = config
 	return nil
 }
+
+// GetMaxConcurrent defaults to 4 - summarization is a lighter, less
+// rate-limit-sensitive call than transcription. Override via
+// configuration["maxConcurrent"].
+func (s *SummaryParserImpl) GetMaxConcurrent() int {
+	if n, ok := s.configuration["maxConcurrent"].(float64); ok && n > 0 {
+		return int(n)
+	}
+	return 4
+}
+
+// GetResourceClaim is nominal: summarization is a call to the configured
+// LLM provider's API (OpenAI, Anthropic, or an Ollama server), not compute
+// in this process.
+func (s *SummaryParserImpl) GetResourceClaim() ResourceClaim {
+	return ResourceClaim{CPU: 1, GPU: false, MemMB: 128}
+}
+
+// GetSchedulerWeight defaults to 2 - summaries are quick, so giving them a
+// bigger share of each dispatch round keeps a handful of long-running
+// transcriptions from starving them out entirely. Override via
+// configuration["schedulerWeight"].
+func (s *SummaryParserImpl) GetSchedulerWeight() int {
+	if n, ok := s.configuration["schedulerWeight"].(float64); ok && n > 0 {
+		return int(n)
+	}
+	return 2
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// pipelineAdvanceInterval is how often advancePipelines sweeps completed
+// jobs for a pending next stage to queue.
+const pipelineAdvanceInterval = 2 * time.Second
+
+// SubmitPipelineSpec materializes spec's first stage into queued
+// JobRequests, one per file in spec.FolderID matching that stage's glob.
+// Later stages aren't queued yet - they're chained automatically by
+// advancePipelines once their upstream job's completedAt is set, so the
+// DAG never runs a downstream stage before the file it depends on exists.
+func (j *JobQueueServiceImpl) SubmitPipelineSpec(spec *PipelineSpec) (int, error) {
+	files, err := j.filesInFolder(spec.FolderID)
+	if err != nil {
+		return 0, err
+	}
+
+	first := spec.Pipeline[0]
+	rest := spec.Pipeline[1:]
+
+	var matched []FileRecord
+	for _, file := range files {
+		if ok, _ := doublestar.Match(first.Glob, file.Path); ok {
+			matched = append(matched, file)
+		}
+	}
+
+	if spec.CostCapUSD > 0 {
+		if err := j.checkPipelineCostCap(spec, first, matched); err != nil {
+			return 0, err
+		}
+	}
+
+	restJSON, err := json.Marshal(rest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode pipeline stages: %w", err)
+	}
+
+	queued := 0
+	for _, file := range matched {
+		job := JobRequest{
+			FileID:   file.ID,
+			ParserID: first.ParserID,
+			JobType:  "parse",
+			Priority: first.Priority,
+			Metadata: map[string]interface{}{
+				"pipelineStage":      first.Name,
+				"pipelineFolderId":   spec.FolderID,
+				"pipelineStagesJSON": string(restJSON),
+			},
+		}
+		if err := j.QueueJob(job); err != nil {
+			log.Printf("Warning: failed to queue pipeline stage %q for file %s: %v", first.Name, file.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// checkPipelineCostCap rejects the whole run upfront if the entry stage's
+// estimated cost alone would exceed spec.CostCapUSD. Downstream stages
+// aren't included since their input files don't exist until their
+// upstream completes, so their cost can't be estimated ahead of time.
+func (j *JobQueueServiceImpl) checkPipelineCostCap(spec *PipelineSpec, first PipelineStageSpec, files []FileRecord) error {
+	parser, err := j.parserManager.GetParser(first.ParserID)
+	if err != nil {
+		return fmt.Errorf("failed to get parser %s for cost estimate: %w", first.ParserID, err)
+	}
+
+	var total float64
+	for _, file := range files {
+		cost, err := parser.EstimateCost(file.Path)
+		if err != nil {
+			log.Printf("Warning: failed to estimate cost for %s: %v", file.Path, err)
+			continue
+		}
+		total += cost
+	}
+
+	if total > spec.CostCapUSD {
+		return fmt.Errorf("estimated cost $%.2f for stage %q exceeds cost cap $%.2f", total, first.Name, spec.CostCapUSD)
+	}
+
+	return nil
+}
+
+// filesInFolder fetches every file record for folderId via files:getByFolder,
+// the same Convex call GetFolderFiles uses - a
+// pipeline run needs each file's raw path to match against a stage's glob
+// and the same FileID processJob already knows how to resolve.
+func (j *JobQueueServiceImpl) filesInFolder(folderId string) ([]FileRecord, error) {
+	result, err := j.convexClient.CallQuery("files:getByFolder", map[string]interface{}{
+		"folderId": folderId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files for folder %s: %w", folderId, err)
+	}
+
+	list, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid files data for folder %s", folderId)
+	}
+
+	files := make([]FileRecord, 0, len(list))
+	for _, item := range list {
+		fileMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		files = append(files, fileRecordFromMap(fileMap))
+	}
+	return files, nil
+}
+
+// pipelineAdvancer sweeps for completed jobs with a pending next stage
+// every pipelineAdvanceInterval until ctx is cancelled.
+func (j *JobQueueServiceImpl) pipelineAdvancer(ctx context.Context) {
+	ticker := time.NewTicker(pipelineAdvanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.advancePipelines()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// advancePipelines lists every job the store knows about and queues the
+// next pipeline stage for any that completed with a pending one recorded
+// in their metadata. It's a no-op if the active store doesn't support
+// JobLister (full enumeration isn't needed on the hot path elsewhere, so
+// not every backend has to implement it).
+func (j *JobQueueServiceImpl) advancePipelines() {
+	lister, ok := j.store.(JobLister)
+	if !ok {
+		return
+	}
+
+	jobs, err := lister.ListAll()
+	if err != nil {
+		log.Printf("Warning: failed to list jobs for pipeline advancement: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.Status != "completed" {
+			continue
+		}
+		if stagesJSON, _ := job.Metadata["pipelineStagesJSON"].(string); stagesJSON != "" {
+			if err := j.advancePipelineJob(job); err != nil {
+				log.Printf("Warning: failed to advance pipeline past job %s: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+// advancePipelineJob queues job's next pipeline stage (if any) against its
+// outputPath, then clears job's pipelineStagesJSON marker so it isn't
+// reconsidered on the next sweep regardless of whether queuing the next
+// stage succeeded.
+func (j *JobQueueServiceImpl) advancePipelineJob(job JobRecord) error {
+	stagesJSON, _ := job.Metadata["pipelineStagesJSON"].(string)
+
+	var rest []PipelineStageSpec
+	if err := json.Unmarshal([]byte(stagesJSON), &rest); err != nil {
+		return fmt.Errorf("failed to decode remaining pipeline stages: %w", err)
+	}
+
+	if err := j.store.UpdateMetadata(job.ID, map[string]interface{}{"pipelineStagesJSON": ""}); err != nil {
+		return fmt.Errorf("failed to clear pipeline marker: %w", err)
+	}
+
+	if len(rest) == 0 {
+		return nil
+	}
+
+	outputPath, _ := job.Metadata["outputPath"].(string)
+	if outputPath == "" {
+		return fmt.Errorf("completed job has no outputPath to chain from")
+	}
+
+	next := rest[0]
+	remaining := rest[1:]
+
+	if next.OutputDir != "" {
+		moved, err := routeOutput(outputPath, next.OutputDir)
+		if err != nil {
+			log.Printf("Warning: failed to route output %s to %s, leaving it in place: %v", outputPath, next.OutputDir, err)
+		} else {
+			outputPath = moved
+		}
+	}
+
+	registrar, ok := j.store.(FileRegistrar)
+	if !ok {
+		return fmt.Errorf("job store does not support registering pipeline output files")
+	}
+
+	folderId, _ := job.Metadata["pipelineFolderId"].(string)
+	fileId, err := registrar.RegisterFile(FileRecord{
+		Path:     outputPath,
+		FileType: fileTypeForPath(outputPath),
+		FolderID: folderId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register pipeline output file: %w", err)
+	}
+
+	remainingJSON, err := json.Marshal(remaining)
+	if err != nil {
+		return fmt.Errorf("failed to encode remaining pipeline stages: %w", err)
+	}
+
+	return j.QueueJob(JobRequest{
+		FileID:   fileId,
+		ParserID: next.ParserID,
+		JobType:  "parse",
+		Priority: next.Priority,
+		Metadata: map[string]interface{}{
+			"pipelineStage":      next.Name,
+			"pipelineFolderId":   folderId,
+			"pipelineStagesJSON": string(remainingJSON),
+		},
+	})
+}
+
+// routeOutput moves path into dir (creating it if necessary) for stages
+// declaring an outputDir, and returns its new location.
+func routeOutput(path, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output dir %s: %w", dir, err)
+	}
+
+	dest := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to move %s to %s: %w", path, dest, err)
+	}
+	return dest, nil
+}
+
+// fileTypeForPath classifies a pipeline stage's output the same way
+// fileTypeExtensions classifies any other file, falling back to "other"
+// for extensions it doesn't recognize.
+func fileTypeForPath(path string) string {
+	if fileType, ok := fileTypeExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return fileType
+	}
+	return "other"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineSpec is the top-level shape of a declarative spec file submitted
+// via `voice-worker run <spec.yaml>` / CleanApp.SubmitSpec: which folder to
+// scan and the ordered stage chain applied to every file its first stage
+// matches.
+type PipelineSpec struct {
+	FolderID   string              `yaml:"folder"`
+	CostCapUSD float64             `yaml:"costCap"`
+	Pipeline   []PipelineStageSpec `yaml:"pipeline"`
+}
+
+// PipelineStageSpec is one stage in a pipeline run. Only the first stage
+// declares Glob, since it selects which of the folder's files enter the
+// pipeline; every later stage's input is implicitly the previous stage's
+// own output, chained once that job's completedAt is set (see
+// JobQueueServiceImpl.advancePipelines).
+type PipelineStageSpec struct {
+	Name      string                 `yaml:"name"`
+	Glob      string                 `yaml:"glob,omitempty"`
+	ParserID  string                 `yaml:"parser"`
+	Priority  int                    `yaml:"priority"`
+	OutputDir string                 `yaml:"outputDir,omitempty"`
+	Config    map[string]interface{} `yaml:"config,omitempty"`
+}
+
+// ParsePipelineSpec reads and validates a YAML pipeline spec from path.
+func ParsePipelineSpec(path string) (*PipelineSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline spec %s: %w", path, err)
+	}
+
+	var spec PipelineSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline spec %s: %w", path, err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline spec %s: %w", path, err)
+	}
+
+	return &spec, nil
+}
+
+// Validate checks that the spec is well-formed enough to materialize into
+// a DAG of JobRequests: a folder to scan, at least one stage, every stage
+// naming a parser, and a glob on the first stage to select input files.
+func (s *PipelineSpec) Validate() error {
+	if s.FolderID == "" {
+		return fmt.Errorf("folder is required")
+	}
+	if len(s.Pipeline) == 0 {
+		return fmt.Errorf("pipeline must declare at least one stage")
+	}
+	if s.Pipeline[0].Glob == "" {
+		return fmt.Errorf("stage %q (the first stage) must set glob", s.Pipeline[0].Name)
+	}
+	for i, stage := range s.Pipeline {
+		if stage.Name == "" {
+			return fmt.Errorf("stage %d must set name", i)
+		}
+		if stage.ParserID == "" {
+			return fmt.Errorf("stage %q must set parser", stage.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-audio/wav"
+	"github.com/gordonklaus/portaudio"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+var playbackLog = GetFacility("playback")
+
+// playbackProgressInterval controls how often playbackProgress events are
+// emitted while a file is playing, so the frontend can render a scrubber
+// without being flooded from the audio callback itself.
+const playbackProgressInterval = 200 * time.Millisecond
+
+// PlaybackService is the output-side counterpart to AudioService: it opens
+// a PortAudio output stream and plays back a decoded audio file, so users
+// can audition folder-monitored files or a just-finished recording without
+// shelling out to an external player.
+type PlaybackService struct {
+	ctx context.Context
+
+	mutex          sync.Mutex
+	outputDevices  []*portaudio.DeviceInfo
+	selectedDevice *portaudio.DeviceInfo
+
+	stream     *portaudio.Stream
+	samples    []int16
+	sampleRate float64
+	channels   int
+	position   int // index into samples, always a multiple of channels
+
+	isPlaying bool
+	isPaused  bool
+	stopDone  chan struct{}
+}
+
+// NewPlaybackService creates a new PlaybackService.
+func NewPlaybackService() *PlaybackService {
+	return &PlaybackService{}
+}
+
+// SetContext sets the application context for the service.
+func (p *PlaybackService) SetContext(ctx context.Context) {
+	p.ctx = ctx
+}
+
+// SelectOutputDevice selects the PortAudio output device to play through by
+// name. Call ListOutputDevices (or let SelectOutputDevice populate the
+// cache) before this if the device list hasn't been fetched yet.
+func (p *PlaybackService) SelectOutputDevice(name string) error {
+	if p.outputDevices == nil {
+		if _, err := p.ListOutputDevices(); err != nil {
+			return fmt.Errorf("could not list output devices: %w", err)
+		}
+	}
+
+	for _, device := range p.outputDevices {
+		if device.Name == name && device.MaxOutputChannels > 0 {
+			p.mutex.Lock()
+			p.selectedDevice = device
+			p.mutex.Unlock()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("output device not found: %s", name)
+}
+
+// ListOutputDevices returns the names of available audio output devices,
+// caching the full device list for internal use.
+func (p *PlaybackService) ListOutputDevices() ([]string, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	p.outputDevices = devices
+
+	var names []string
+	for _, device := range devices {
+		if device.MaxOutputChannels > 0 {
+			names = append(names, device.Name)
+		}
+	}
+	return names, nil
+}
+
+// PlayFile decodes the file at path and starts playing it through the
+// selected output device (or the system default, if none has been
+// selected). Any currently-playing file is stopped first.
+func (p *PlaybackService) PlayFile(path string) error {
+	p.StopPlayback()
+
+	samples, sampleRate, channels, err := decodeAudioFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	device := p.selectedDevice
+	if device == nil {
+		device, err = portaudio.DefaultOutputDevice()
+		if err != nil {
+			return fmt.Errorf("failed to get default output device: %w", err)
+		}
+	}
+
+	p.mutex.Lock()
+	p.samples = samples
+	p.sampleRate = sampleRate
+	p.channels = channels
+	p.position = 0
+	p.isPaused = false
+	p.mutex.Unlock()
+
+	streamParameters := portaudio.StreamParameters{
+		Output: portaudio.StreamDeviceParameters{
+			Device:   device,
+			Channels: channels,
+			Latency:  device.DefaultLowOutputLatency,
+		},
+		SampleRate:      sampleRate,
+		FramesPerBuffer: 256,
+	}
+
+	stream, err := portaudio.OpenStream(streamParameters, p.playbackCallback)
+	if err != nil {
+		return fmt.Errorf("failed to open output stream on device %s: %w", device.Name, err)
+	}
+
+	if err := stream.Start(); err != nil {
+		return fmt.Errorf("failed to start output stream: %w", err)
+	}
+
+	p.mutex.Lock()
+	p.stream = stream
+	p.isPlaying = true
+	p.stopDone = make(chan struct{})
+	p.mutex.Unlock()
+
+	go p.emitProgress()
+
+	playbackLog.Debugf("Playing %s (%d samples @ %.0fHz, %d ch)\n", path, len(samples), sampleRate, channels)
+	return nil
+}
+
+// playbackCallback fills the output buffer from the decoded sample slice,
+// advancing position unless playback is paused. It runs on the PortAudio
+// callback goroutine, so it must stay allocation-free and never block.
+func (p *PlaybackService) playbackCallback(out []int16) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.isPaused {
+		for i := range out {
+			out[i] = 0
+		}
+		return
+	}
+
+	for i := range out {
+		if p.position >= len(p.samples) {
+			out[i] = 0
+			continue
+		}
+		out[i] = p.samples[p.position]
+		p.position++
+	}
+
+	if p.position >= len(p.samples) {
+		go p.StopPlayback()
+	}
+}
+
+// emitProgress periodically emits playbackProgress events with the current
+// position in seconds until playback stops.
+func (p *PlaybackService) emitProgress() {
+	ticker := time.NewTicker(playbackProgressInterval)
+	defer ticker.Stop()
+
+	p.mutex.Lock()
+	done := p.stopDone
+	p.mutex.Unlock()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			p.mutex.Lock()
+			position := p.position
+			sampleRate := p.sampleRate
+			channels := p.channels
+			p.mutex.Unlock()
+
+			if channels == 0 || sampleRate == 0 {
+				continue
+			}
+
+			seconds := float64(position/channels) / sampleRate
+			runtime.EventsEmit(p.ctx, "playbackProgress", seconds)
+		}
+	}
+}
+
+// PausePlayback pauses playback in place; the stream keeps running but
+// outputs silence so it can resume without reopening the device.
+func (p *PlaybackService) PausePlayback() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isPlaying {
+		return fmt.Errorf("not playing")
+	}
+	p.isPaused = true
+	return nil
+}
+
+// ResumePlayback resumes a paused playback.
+func (p *PlaybackService) ResumePlayback() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isPlaying {
+		return fmt.Errorf("not playing")
+	}
+	p.isPaused = false
+	return nil
+}
+
+// StopPlayback stops and closes the output stream, if one is open.
+func (p *PlaybackService) StopPlayback() error {
+	p.mutex.Lock()
+	stream := p.stream
+	stopDone := p.stopDone
+	p.stream = nil
+	p.isPlaying = false
+	p.isPaused = false
+	p.position = 0
+	p.mutex.Unlock()
+
+	if stream == nil {
+		return nil
+	}
+
+	if stopDone != nil {
+		close(stopDone)
+	}
+
+	if err := stream.Stop(); err != nil {
+		return fmt.Errorf("failed to stop output stream: %w", err)
+	}
+	if err := stream.Close(); err != nil {
+		return fmt.Errorf("failed to close output stream: %w", err)
+	}
+
+	playbackLog.Debugln("Playback stopped.")
+	return nil
+}
+
+// SeekPlayback jumps playback to the given offset, in seconds.
+func (p *PlaybackService) SeekPlayback(seconds float64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isPlaying {
+		return fmt.Errorf("not playing")
+	}
+	if p.sampleRate == 0 || p.channels == 0 {
+		return fmt.Errorf("no audio loaded")
+	}
+
+	frame := int(seconds * p.sampleRate)
+	pos := frame * p.channels
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > len(p.samples) {
+		pos = len(p.samples)
+	}
+	p.position = pos
+	return nil
+}
+
+// decodeAudioFile decodes a WAV file into interleaved int16 samples,
+// returning the sample rate and channel count alongside the data. MP3 and
+// FLAC files are recognized but not decoded: they return an error, as does
+// any other extension.
+func decodeAudioFile(path string) ([]int16, float64, int, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch ext {
+	case ".wav":
+		return decodeWAVFile(path)
+	case ".mp3", ".flac":
+		// MP3/FLAC decoding requires a format-specific decoder
+		// (e.g. github.com/hajimehoshi/go-mp3, github.com/mewkiz/flac);
+		// wiring those up is tracked separately from this change.
+		return nil, 0, 0, fmt.Errorf("playback of %s files is not yet supported", ext)
+	default:
+		return nil, 0, 0, fmt.Errorf("unsupported audio format: %s", ext)
+	}
+}
+
+func decodeWAVFile(path string) ([]int16, float64, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer file.Close()
+
+	decoder := wav.NewDecoder(file)
+	if !decoder.IsValidFile() {
+		return nil, 0, 0, fmt.Errorf("not a valid WAV file")
+	}
+
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to decode WAV data: %w", err)
+	}
+
+	samples := make([]int16, len(buf.Data))
+	for i, sample := range buf.Data {
+		samples[i] = int16(sample)
+	}
+
+	return samples, float64(buf.Format.SampleRate), buf.Format.NumChannels, nil
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var pluginLog = GetFacility("plugin")
+
+// pluginCallTimeout bounds every RPC except "process", which instead
+// honors the ctx passed to Parser.Process so a slow transcription isn't
+// cut off at an arbitrary fixed timeout.
+const pluginCallTimeout = 10 * time.Second
+
+// pluginHandshakeTimeout bounds how long a freshly spawned plugin has to
+// complete the handshake + describe sequence before it's considered dead
+// on arrival.
+const pluginHandshakeTimeout = 5 * time.Second
+
+// PluginParser implements Parser by shelling out to a subprocess speaking
+// pluginMessage JSON-RPC over stdio, so a new transcription/summarization
+// engine can be added by dropping a binary into the plugins directory
+// instead of recompiling the app - see PluginRegistry, which discovers and
+// spawns these.
+type PluginParser struct {
+	execPath string
+	workDir  string
+	limits   pluginResourceLimits
+
+	mutex  sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	nextID int64
+	// exited is closed by a goroutine that calls cmd.Wait() right after
+	// cmd.Start(), since nothing else in this type ever reaps the child -
+	// without it, cmd.ProcessState would stay nil forever and a crashed
+	// plugin would look indistinguishable from a running one.
+	exited chan struct{}
+
+	// Populated from the "describe" response during start(), and fixed for
+	// the process's lifetime - a plugin can't change its own identity
+	// without a restart.
+	id              string
+	name            string
+	inputTypes      []string
+	outputExtension string
+	outputSuffix    string
+	maxConcurrent   int
+	resourceClaim   ResourceClaim
+	schedulerWeight int
+
+	configMu      sync.RWMutex
+	configuration map[string]interface{}
+}
+
+// newPluginParser spawns execPath, runs the handshake + describe sequence,
+// and returns a ready-to-use PluginParser. workDir sandboxes the
+// subprocess's working directory; limits are applied via
+// setRlimitForPID once the process starts, which is a no-op on platforms
+// other than Linux.
+func newPluginParser(execPath, workDir string, limits pluginResourceLimits) (*PluginParser, error) {
+	p := &PluginParser{
+		execPath:      execPath,
+		workDir:       workDir,
+		limits:        limits,
+		configuration: make(map[string]interface{}),
+	}
+
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// startLocked spawns the plugin subprocess and blocks until the handshake and
+// describe sequence completes, populating this PluginParser's identity
+// fields. Callers must hold p.mutex.
+func (p *PluginParser) startLocked() error {
+	cmd := exec.Command(p.execPath)
+	cmd.Dir = p.workDir
+	applyProcessSandbox(cmd, p.limits)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open plugin stdout: %w", err)
+	}
+	cmd.Stderr = &pluginStderrWriter{execPath: p.execPath}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin %s: %w", p.execPath, err)
+	}
+	setRlimitForPID(cmd.Process.Pid, p.limits)
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.stdout = bufio.NewScanner(stdout)
+	p.stdout.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	p.exited = make(chan struct{})
+	go func(c *exec.Cmd, exited chan struct{}) {
+		c.Wait()
+		close(exited)
+	}(cmd, p.exited)
+
+	handshake, err := p.callLocked(pluginHandshakeTimeout, "handshake", pluginHandshakeParams{
+		Magic:       pluginProtocolMagic,
+		HostVersion: pluginProtocolVersion,
+	})
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("plugin %s failed handshake: %w", p.execPath, err)
+	}
+	var hs pluginHandshakeResult
+	if err := json.Unmarshal(handshake, &hs); err != nil || hs.Magic != pluginProtocolMagic {
+		cmd.Process.Kill()
+		return fmt.Errorf("plugin %s returned an invalid handshake", p.execPath)
+	}
+	if hs.Version != pluginProtocolVersion {
+		pluginLog.Debugf("plugin %s speaks protocol version %d, host speaks %d\n", p.execPath, hs.Version, pluginProtocolVersion)
+	}
+
+	describe, err := p.callLocked(pluginHandshakeTimeout, "describe", nil)
+	if err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("plugin %s failed describe: %w", p.execPath, err)
+	}
+	var d pluginDescribeResult
+	if err := json.Unmarshal(describe, &d); err != nil {
+		cmd.Process.Kill()
+		return fmt.Errorf("plugin %s returned an invalid describe response: %w", p.execPath, err)
+	}
+
+	p.id = d.ID
+	p.name = d.Name
+	p.inputTypes = d.InputTypes
+	p.outputExtension = d.OutputExtension
+	p.outputSuffix = d.OutputSuffix
+	p.maxConcurrent = d.MaxConcurrent
+	p.resourceClaim = d.ResourceClaim
+	p.schedulerWeight = d.SchedulerWeight
+
+	pluginLog.Debugf("plugin %s started as parser %s (pid %d)\n", p.execPath, p.id, cmd.Process.Pid)
+	return nil
+}
+
+func (p *PluginParser) start() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.startLocked()
+}
+
+// ensureRunningLocked restarts the subprocess if it's exited since the last
+// call, so a crashed plugin recovers on the next job instead of failing
+// every job forever. Callers must hold p.mutex.
+func (p *PluginParser) ensureRunningLocked() error {
+	if p.cmd != nil {
+		select {
+		case <-p.exited:
+			// fall through to restart
+		default:
+			return nil
+		}
+	}
+	pluginLog.Debugf("plugin %s not running, restarting\n", p.execPath)
+	return p.startLocked()
+}
+
+// ping sends a lightweight health-check request, restarting the plugin if
+// it doesn't respond. Called periodically by PluginRegistry so a crash is
+// caught even for a plugin with no job currently in flight.
+func (p *PluginParser) ping() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.ensureRunningLocked(); err != nil {
+		return err
+	}
+	_, err := p.callLocked(pluginCallTimeout, "ping", nil)
+	if err != nil {
+		pluginLog.Debugf("plugin %s failed health check, restarting: %v\n", p.execPath, err)
+		if restartErr := p.startLocked(); restartErr != nil {
+			return restartErr
+		}
+	}
+	return nil
+}
+
+// stop kills the plugin subprocess. It's used by PluginRegistry.Stop/Reload
+// to tear down a plugin that's being replaced or the app is shutting down.
+func (p *PluginParser) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.cmd != nil && p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+}
+
+// callLocked sends a request and blocks for its matching response,
+// skipping any progress notification in the meantime
+// (callLockedWithProgress forwards those to onProgress instead). Callers
+// must hold p.mutex.
+func (p *PluginParser) callLocked(timeout time.Duration, method string, params interface{}) (json.RawMessage, error) {
+	return p.callLockedWithProgress(timeout, method, params, nil)
+}
+
+func (p *PluginParser) callLockedWithProgress(timeout time.Duration, method string, params interface{}, onProgress func(ProgressEvent)) (json.RawMessage, error) {
+	id := atomic.AddInt64(&p.nextID, 1)
+
+	var paramsJSON json.RawMessage
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		paramsJSON = encoded
+	}
+
+	req := pluginMessage{ID: id, Method: method, Params: paramsJSON}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	// Scan blocks until the plugin writes a line, so a plugin that stays
+	// silent past the deadline is killed to unblock it.
+	var timedOut int32
+	if timeout > 0 {
+		cmd := p.cmd
+		timer := time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&timedOut, 1)
+			cmd.Process.Kill()
+		})
+		defer timer.Stop()
+	}
+	for {
+		if timeout > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("plugin %s timed out waiting for %s response", p.execPath, method)
+		}
+
+		if !p.stdout.Scan() {
+			if atomic.LoadInt32(&timedOut) == 1 {
+				return nil, fmt.Errorf("plugin %s timed out waiting for %s response", p.execPath, method)
+			}
+			if err := p.stdout.Err(); err != nil {
+				return nil, fmt.Errorf("plugin %s stdout closed: %w", p.execPath, err)
+			}
+			return nil, fmt.Errorf("plugin %s stdout closed unexpectedly", p.execPath)
+		}
+
+		var msg pluginMessage
+		if err := json.Unmarshal(p.stdout.Bytes(), &msg); err != nil {
+			pluginLog.Debugf("plugin %s sent an unparseable line, skipping: %v\n", p.execPath, err)
+			continue
+		}
+
+		if msg.Method == "progress" {
+			var event ProgressEvent
+			if err := json.Unmarshal(msg.Params, &event); err == nil && onProgress != nil {
+				onProgress(event)
+			}
+			continue
+		}
+
+		if msg.ID != id {
+			continue
+		}
+		if msg.Error != "" {
+			return nil, fmt.Errorf("plugin %s returned an error for %s: %s", p.execPath, method, msg.Error)
+		}
+		return msg.Result, nil
+	}
+}
+
+func (p *PluginParser) GetID() string {
+	return p.id
+}
+
+func (p *PluginParser) GetName() string {
+	return p.name
+}
+
+func (p *PluginParser) GetInputTypes() []string {
+	return p.inputTypes
+}
+
+func (p *PluginParser) GetOutputExtension() string {
+	return p.outputExtension
+}
+
+func (p *PluginParser) GetOutputSuffix() string {
+	return p.outputSuffix
+}
+
+func (p *PluginParser) CanProcess(filePath, fileType string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.ensureRunningLocked(); err != nil {
+		pluginLog.Debugf("plugin %s unavailable for CanProcess: %v\n", p.execPath, err)
+		return false
+	}
+
+	result, err := p.callLocked(pluginCallTimeout, "canProcess", pluginCanProcessParams{FilePath: filePath, FileType: fileType})
+	if err != nil {
+		pluginLog.Debugf("plugin %s CanProcess call failed: %v\n", p.execPath, err)
+		return false
+	}
+
+	var decoded pluginCanProcessResult
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		return false
+	}
+	return decoded.CanProcess
+}
+
+// Process sends a "process" request and blocks until the plugin reports
+// completion (or ctx is cancelled). Progress notifications streamed in the
+// meantime are logged against the plugin facility, since Parser.Process's
+// signature is shared with every built-in parser and has no room for a
+// progress callback.
+func (p *PluginParser) Process(ctx context.Context, inputPath, outputPath string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.ensureRunningLocked(); err != nil {
+		return fmt.Errorf("plugin %s unavailable: %w", p.execPath, err)
+	}
+
+	p.configMu.RLock()
+	config := p.configuration
+	p.configMu.RUnlock()
+
+	resultCh := make(chan error, 1)
+	go func() {
+		_, err := p.callLockedWithProgress(0, "process", pluginProcessParams{
+			InputPath:  inputPath,
+			OutputPath: outputPath,
+			Config:     config,
+		}, func(event ProgressEvent) {
+			pluginLog.Debugf("plugin %s: %.0f%% %s\n", p.execPath, event.Percent*100, event.Message)
+		})
+		resultCh <- err
+	}()
+
+	select {
+	case err := <-resultCh:
+		return err
+	case <-ctx.Done():
+		if p.cmd != nil && p.cmd.Process != nil {
+			p.cmd.Process.Kill()
+		}
+		<-resultCh
+		return ctx.Err()
+	}
+}
+
+func (p *PluginParser) EstimateCost(filePath string) (float64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.ensureRunningLocked(); err != nil {
+		return 0, err
+	}
+
+	result, err := p.callLocked(pluginCallTimeout, "estimateCost", pluginEstimateCostParams{FilePath: filePath})
+	if err != nil {
+		return 0, err
+	}
+
+	var decoded pluginEstimateCostResult
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		return 0, fmt.Errorf("plugin %s returned an invalid estimateCost response: %w", p.execPath, err)
+	}
+	return decoded.Cost, nil
+}
+
+func (p *PluginParser) GetConfiguration() map[string]interface{} {
+	p.configMu.RLock()
+	defer p.configMu.RUnlock()
+	return p.configuration
+}
+
+func (p *PluginParser) SetConfiguration(config map[string]interface{}) error {
+	p.configMu.Lock()
+	p.configuration = config
+	p.configMu.Unlock()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.ensureRunningLocked(); err != nil {
+		return err
+	}
+	_, err := p.callLocked(pluginCallTimeout, "setConfiguration", pluginSetConfigurationParams{Config: config})
+	return err
+}
+
+func (p *PluginParser) GetMaxConcurrent() int {
+	if p.maxConcurrent <= 0 {
+		return 1
+	}
+	return p.maxConcurrent
+}
+
+func (p *PluginParser) GetResourceClaim() ResourceClaim {
+	return p.resourceClaim
+}
+
+// GetSchedulerWeight defaults to 1, same as a built-in parser with no
+// configured override - a plugin that wants a different share of each
+// dispatch round declares SchedulerWeight in its describe response.
+func (p *PluginParser) GetSchedulerWeight() int {
+	if p.schedulerWeight <= 0 {
+		return 1
+	}
+	return p.schedulerWeight
+}
+
+// pluginStderrWriter forwards a plugin subprocess's stderr to the plugin
+// facility line-by-line, so a crashing plugin's own diagnostics show up in
+// the Debug panel instead of being silently discarded.
+type pluginStderrWriter struct {
+	execPath string
+	buf      strings.Builder
+}
+
+func (w *pluginStderrWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '\n' {
+			pluginLog.Debugf("plugin %s stderr: %s\n", w.execPath, w.buf.String())
+			w.buf.Reset()
+			continue
+		}
+		w.buf.WriteByte(b)
+	}
+	return len(p), nil
+}
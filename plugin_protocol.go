@@ -0,0 +1,98 @@
+package main
+
+import "encoding/json"
+
+// pluginProtocolMagic is exchanged during the handshake so a PluginParser
+// refuses to talk to a subprocess that isn't actually speaking this
+// protocol (e.g. a misconfigured plugins/ entry that happens to be
+// executable).
+const pluginProtocolMagic = "voiceworker-plugin-v1"
+
+// pluginProtocolVersion is advertised to the plugin during the handshake.
+// Plugins may use it to pick a compatible response shape if the protocol
+// grows a v2 later; nothing in this host enforces it beyond logging a
+// mismatch.
+const pluginProtocolVersion = 1
+
+// pluginMessage is the single newline-delimited JSON shape exchanged in
+// both directions over a plugin's stdin/stdout. A request sets Method and
+// ID; a response sets ID and either Result or Error; a notification (used
+// only for progress streaming during "process") sets Method with no ID.
+type pluginMessage struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// pluginHandshakeParams is sent as the first message on a freshly spawned
+// plugin process.
+type pluginHandshakeParams struct {
+	Magic       string `json:"magic"`
+	HostVersion int    `json:"hostVersion"`
+}
+
+// pluginHandshakeResult is the expected reply to a handshake request.
+type pluginHandshakeResult struct {
+	Magic        string   `json:"magic"`
+	Version      int      `json:"version"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// pluginDescribeResult is the expected reply to a "describe" request,
+// populating the same fields a built-in Parser hardcodes in its struct
+// literal.
+type pluginDescribeResult struct {
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	InputTypes      []string      `json:"inputTypes"`
+	OutputExtension string        `json:"outputExtension"`
+	OutputSuffix    string        `json:"outputSuffix"`
+	MaxConcurrent   int           `json:"maxConcurrent"`
+	ResourceClaim   ResourceClaim `json:"resourceClaim"`
+	SchedulerWeight int           `json:"schedulerWeight"`
+}
+
+// pluginProcessParams is sent with a "process" request.
+type pluginProcessParams struct {
+	InputPath  string                 `json:"inputPath"`
+	OutputPath string                 `json:"outputPath"`
+	Config     map[string]interface{} `json:"config"`
+}
+
+// ProgressEvent is streamed by a plugin via "progress" notifications while
+// a "process" request is in flight. The host logs these against the
+// plugin facility rather than surfacing them through Parser.Process's
+// return value, since that interface is shared with every built-in parser
+// and isn't streaming-aware.
+type ProgressEvent struct {
+	Percent float64 `json:"percent"`
+	Message string  `json:"message"`
+}
+
+// pluginEstimateCostParams is sent with an "estimateCost" request.
+type pluginEstimateCostParams struct {
+	FilePath string `json:"filePath"`
+}
+
+// pluginEstimateCostResult is the expected reply to an "estimateCost" request.
+type pluginEstimateCostResult struct {
+	Cost float64 `json:"cost"`
+}
+
+// pluginCanProcessParams is sent with a "canProcess" request.
+type pluginCanProcessParams struct {
+	FilePath string `json:"filePath"`
+	FileType string `json:"fileType"`
+}
+
+// pluginCanProcessResult is the expected reply to a "canProcess" request.
+type pluginCanProcessResult struct {
+	CanProcess bool `json:"canProcess"`
+}
+
+// pluginSetConfigurationParams is sent with a "setConfiguration" request.
+type pluginSetConfigurationParams struct {
+	Config map[string]interface{} `json:"config"`
+}
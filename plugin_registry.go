@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pluginHealthCheckInterval is how often PluginRegistry pings every loaded
+// plugin, so a crashed plugin is restarted even if nothing happens to be
+// processing through it at the moment.
+const pluginHealthCheckInterval = 30 * time.Second
+
+// PluginRegistry discovers out-of-process parsers under a directory and
+// manages their lifecycle (spawn, health-check, restart-on-crash, and a
+// Reload that re-scans the directory when called - nothing watches the
+// directory for changes), the same role
+// initializeBuiltInParsers plays for in-process parsers.
+type PluginRegistry struct {
+	dir    string
+	limits pluginResourceLimits
+
+	mutex   sync.RWMutex
+	plugins map[string]*PluginParser // keyed by absolute executable path
+
+	cancel context.CancelFunc
+}
+
+// NewPluginRegistry returns a registry that will discover plugins under
+// dir once Start is called. dir not existing is not an error - it just
+// means no plugins are loaded, the same way an empty plugins/ folder
+// would.
+func NewPluginRegistry(dir string) *PluginRegistry {
+	return &PluginRegistry{
+		dir:     dir,
+		limits:  defaultPluginResourceLimits(),
+		plugins: make(map[string]*PluginParser),
+	}
+}
+
+// Start scans dir for plugin executables, spawns and handshakes with each,
+// and launches the background health-check loop. A plugin that fails to
+// start is logged and skipped rather than failing the whole call, so one
+// bad plugin can't block every other parser (built-in or plugin) from
+// loading.
+func (r *PluginRegistry) Start(ctx context.Context) error {
+	r.loadAll()
+
+	hctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	go r.healthCheckLoop(hctx)
+
+	return nil
+}
+
+// Stop kills every running plugin subprocess and halts the health-check
+// loop.
+func (r *PluginRegistry) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for path, plugin := range r.plugins {
+		plugin.stop()
+		delete(r.plugins, path)
+	}
+}
+
+// Reload re-scans dir, starting any newly-added plugin and stopping any
+// that's been removed since the last scan. Existing, still-present
+// plugins are left running untouched.
+func (r *PluginRegistry) Reload() {
+	discovered := r.discover()
+
+	r.mutex.Lock()
+	for path, plugin := range r.plugins {
+		if _, stillPresent := discovered[path]; !stillPresent {
+			plugin.stop()
+			delete(r.plugins, path)
+			log.Printf("Plugin removed: %s", path)
+		}
+	}
+	r.mutex.Unlock()
+
+	r.loadAll()
+}
+
+// Parsers returns every currently loaded plugin as a Parser, for
+// ParserManagerServiceImpl to register alongside its built-ins.
+func (r *PluginRegistry) Parsers() []Parser {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	parsers := make([]Parser, 0, len(r.plugins))
+	for _, plugin := range r.plugins {
+		parsers = append(parsers, plugin)
+	}
+	return parsers
+}
+
+// discover lists every regular, executable file directly under r.dir. A
+// missing directory yields an empty set rather than an error.
+func (r *PluginRegistry) discover() map[string]bool {
+	found := make(map[string]bool)
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return found
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.Mode()&0111 == 0 {
+			continue
+		}
+		found[filepath.Join(r.dir, entry.Name())] = true
+	}
+
+	return found
+}
+
+// loadAll spawns every discovered plugin not already loaded.
+func (r *PluginRegistry) loadAll() {
+	for path := range r.discover() {
+		r.mutex.RLock()
+		_, alreadyLoaded := r.plugins[path]
+		r.mutex.RUnlock()
+		if alreadyLoaded {
+			continue
+		}
+
+		workDir := filepath.Dir(path)
+		plugin, err := newPluginParser(path, workDir, r.limits)
+		if err != nil {
+			log.Printf("Warning: failed to load plugin %s: %v", path, err)
+			continue
+		}
+
+		r.mutex.Lock()
+		r.plugins[path] = plugin
+		r.mutex.Unlock()
+		log.Printf("Loaded plugin parser %q from %s", plugin.GetID(), path)
+	}
+}
+
+// healthCheckLoop pings every loaded plugin on pluginHealthCheckInterval,
+// relying on PluginParser.ping to restart any that's stopped responding.
+func (r *PluginRegistry) healthCheckLoop(ctx context.Context) {
+	ticker := time.NewTicker(pluginHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.mutex.RLock()
+			plugins := make([]*PluginParser, 0, len(r.plugins))
+			for _, plugin := range r.plugins {
+				plugins = append(plugins, plugin)
+			}
+			r.mutex.RUnlock()
+
+			for _, plugin := range plugins {
+				if err := plugin.ping(); err != nil {
+					log.Printf("Warning: plugin %s failed to restart after a failed health check: %v", plugin.execPath, err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
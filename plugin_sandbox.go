@@ -0,0 +1,18 @@
+package main
+
+// pluginResourceLimits bounds the CPU time and memory a plugin subprocess
+// may use, enforced via setRlimitForPID. Zero means "no limit" for
+// that resource.
+type pluginResourceLimits struct {
+	CPUSeconds uint64
+	MemMB      uint64
+}
+
+// defaultPluginResourceLimits is applied to every plugin PluginRegistry
+// spawns; there is no per-plugin override.
+func defaultPluginResourceLimits() pluginResourceLimits {
+	return pluginResourceLimits{
+		CPUSeconds: 300,
+		MemMB:      2048,
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import "os/exec"
+
+// applyProcessSandbox is a no-op on platforms other than Linux: the
+// parent-death signal and prlimit64 call the Linux build relies on aren't
+// available, so a plugin there runs unsandboxed.
+func applyProcessSandbox(cmd *exec.Cmd, limits pluginResourceLimits) {}
+
+// setRlimitForPID is a no-op on platforms other than Linux.
+func setRlimitForPID(pid int, limits pluginResourceLimits) {}
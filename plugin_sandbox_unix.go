@@ -0,0 +1,42 @@
+//go:build linux
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// applyProcessSandbox puts the plugin subprocess in its own process group
+// and arranges for it to die if this process does, so a crashed or killed
+// host never leaves an orphaned plugin running. Resource limits can't be
+// set until after the process exists, so they're applied separately by
+// setRlimitForPID once cmd.Start() returns.
+func applyProcessSandbox(cmd *exec.Cmd, limits pluginResourceLimits) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+}
+
+// setRlimitForPID applies limits.CPUSeconds/MemMB to an already-started
+// process via the prlimit64 system call. Failures are ignored: a plugin
+// that can't be rlimited still runs, just without the sandboxing.
+func setRlimitForPID(pid int, limits pluginResourceLimits) {
+	if limits.CPUSeconds > 0 {
+		rlimit := syscall.Rlimit{Cur: limits.CPUSeconds, Max: limits.CPUSeconds}
+		prlimit(pid, syscall.RLIMIT_CPU, &rlimit)
+	}
+	if limits.MemMB > 0 {
+		bytes := limits.MemMB * 1024 * 1024
+		rlimit := syscall.Rlimit{Cur: bytes, Max: bytes}
+		prlimit(pid, syscall.RLIMIT_AS, &rlimit)
+	}
+}
+
+// prlimit sets pid's limit for resource. The syscall package has no
+// prlimit wrapper, so this issues prlimit64 directly.
+func prlimit(pid, resource int, limit *syscall.Rlimit) {
+	syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(limit)), 0, 0, 0)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy bounds how httpxDoWithRetry retries a request: up to
+// MaxAttempts total tries, with the delay between attempts growing by
+// Multiplier each time (capped at MaxDelay) and randomized by
+// JitterFraction so a burst of clients retrying together doesn't
+// re-collide on the same schedule.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialDelay   time.Duration
+	Multiplier     float64
+	MaxDelay       time.Duration
+	JitterFraction float64
+}
+
+// defaultRetryPolicy is used for both Convex calls and the Whisper/summary
+// OpenAI calls - there's nothing endpoint-specific enough yet to warrant
+// per-caller tuning.
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialDelay:   250 * time.Millisecond,
+		Multiplier:     2.0,
+		MaxDelay:       10 * time.Second,
+		JitterFraction: 0.2,
+	}
+}
+
+// delayForAttempt returns how long to wait before the given retry attempt
+// (1-indexed: the delay before the 2nd try is delayForAttempt(1)), with
+// +/- JitterFraction of randomization applied.
+func (p RetryPolicy) delayForAttempt(attempt int) time.Duration {
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if max := float64(p.MaxDelay); delay > max {
+		delay = max
+	}
+
+	if p.JitterFraction > 0 {
+		jitter := delay * p.JitterFraction
+		delay += (rand.Float64()*2 - 1) * jitter
+		if delay < 0 {
+			delay = 0
+		}
+	}
+
+	return time.Duration(delay)
+}
+
+// retryableStatusCodes are the HTTP status codes worth retrying - transient
+// server-side or rate-limit conditions, not client errors.
+var retryableStatusCodes = map[int]bool{
+	429: true,
+	502: true,
+	503: true,
+	504: true,
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log"
 )
 
 // Core service interfaces for the file processing pipeline
@@ -25,6 +27,10 @@ type FileWatcherService interface {
 
 	// GetSelectedFolder returns current monitored folder path
 	GetSelectedFolder() string
+
+	// GetSelectedFolderID returns the Convex folder ID of the currently
+	// monitored folder, used by DryRun to list its files.
+	GetSelectedFolderID() string
 }
 
 // ParserManagerService manages parsers and their configurations
@@ -76,6 +82,15 @@ type JobQueueService interface {
 
 	// RetryJob retries a failed job
 	RetryJob(jobId string) error
+
+	// GetJob returns jobId's full detail - its attempt history, next
+	// scheduled retry time, and last error - more than GetQueueStatus's
+	// aggregate counts expose.
+	GetJob(jobId string) (JobDetail, error)
+
+	// WaitForShutdown blocks until a prior StopProcessing call has
+	// finished draining (or force-stopping) every in-flight job
+	WaitForShutdown()
 }
 
 // Parser interface for pluggable parser implementations
@@ -109,16 +124,49 @@ type Parser interface {
 
 	// SetConfiguration updates parser configuration
 	SetConfiguration(config map[string]interface{}) error
+
+	// GetMaxConcurrent returns how many jobs for this parser the worker
+	// pool may run at once, enforced with a per-parser semaphore
+	GetMaxConcurrent() int
+
+	// GetResourceClaim returns the CPU/GPU/memory a single Process call
+	// is expected to use, so the worker pool can avoid overcommitting
+	// the machine across parsers
+	GetResourceClaim() ResourceClaim
+
+	// GetSchedulerWeight returns this parser's quantum in the job
+	// queue's weighted fair scheduler - how much of each dispatch round
+	// it's entitled to relative to other parsers with pending work, so
+	// e.g. a handful of slow transcriptions can't starve a deep backlog
+	// of fast summaries
+	GetSchedulerWeight() int
+}
+
+// ResourceClaim declares how much of the worker pool's global CPU/GPU/
+// memory budget a single Process call is expected to use. The scheduler
+// only dispatches a job once its claim fits the remaining budget, so
+// e.g. a handful of memory-hungry parsers can't all run at once even if
+// their individual MaxConcurrent would otherwise allow it.
+type ResourceClaim struct {
+	CPU   int
+	GPU   bool
+	MemMB int
 }
 
 // Data structures
 
 type JobRequest struct {
-	FileID       string                 `json:"fileId"`
-	ParserID     string                 `json:"parserId"`
-	JobType      string                 `json:"jobType"`
-	Priority     int                    `json:"priority"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	FileID   string                 `json:"fileId"`
+	ParserID string                 `json:"parserId"`
+	JobType  string                 `json:"jobType"`
+	Priority int                    `json:"priority"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// TimeoutSec bounds how long the worker pool lets this job's
+	// parser.Process call run before its derived context is cancelled.
+	// Zero means no deadline, matching the behavior before per-job
+	// timeouts existed.
+	TimeoutSec int `json:"timeoutSec,omitempty"`
 }
 
 type QueueStatus struct {
@@ -128,6 +176,58 @@ type QueueStatus struct {
 	CompletedJobs int `json:"completedJobs"`
 	FailedJobs    int `json:"failedJobs"`
 	PausedJobs    int `json:"pausedJobs"`
+
+	// RetryScheduledJobs counts jobs waiting out their exponential
+	// backoff delay after a failed attempt, before rejoining the
+	// pending queue. DeadLetterJobs counts jobs that exhausted
+	// maxJobRetries and won't be retried automatically.
+	RetryScheduledJobs int `json:"retryScheduledJobs"`
+	DeadLetterJobs     int `json:"deadLetterJobs"`
+
+	// BlockedByBudgetJobs counts jobs checkJobBudget declined to dispatch
+	// because their parser's estimated cost would exceed a configured
+	// daily/monthly/session/per-parser budget. budgetGateSweeper
+	// periodically re-checks and requeues them once spend resets.
+	BlockedByBudgetJobs int `json:"blockedByBudgetJobs"`
+
+	// Live worker pool utilization, populated by JobQueueServiceImpl -
+	// not tracked by JobStore since it's in-process scheduler state, not
+	// persisted job data.
+	ActiveWorkers      int  `json:"activeWorkers"`
+	ResourceCPUInUse   int  `json:"resourceCpuInUse"`
+	ResourceMemInUseMB int  `json:"resourceMemInUseMb"`
+	ResourceGPUInUse   bool `json:"resourceGpuInUse"`
+
+	// Peers lists every node cooperating on this queue when running in
+	// distributed mode, populated from workers:listPeers - empty for a
+	// single-node (or local-store) deployment.
+	Peers []WorkerPeer `json:"peers,omitempty"`
+
+	// PerParser breaks the counts above down by parser ID, so a caller
+	// can tell e.g. "transcription has a deep backlog" from "everything
+	// is backed up" at a glance.
+	PerParser map[string]ParserQueueBreakdown `json:"perParser,omitempty"`
+}
+
+// JobDetail is a single job's full detail: the same JobRecord
+// GetQueueStatus's aggregate counts are rolled up from, plus the retry
+// bookkeeping GetJob exposes that no other call surfaces - every past
+// attempt's outcome and when (if ever) the next one is scheduled.
+type JobDetail struct {
+	JobRecord
+
+	AttemptHistory  []JobAttempt `json:"attemptHistory,omitempty"`
+	NextScheduledAt int64        `json:"nextScheduledAt,omitempty"`
+	LastError       string       `json:"lastError,omitempty"`
+}
+
+// JobAttempt records the outcome of one past run of a job, appended to its
+// AttemptHistory each time handleJobFailure schedules a retry or
+// dead-letters it.
+type JobAttempt struct {
+	Attempt     int    `json:"attempt"`
+	Error       string `json:"error"`
+	AttemptedAt int64  `json:"attemptedAt"`
 }
 
 // Main service coordinator
@@ -135,6 +235,7 @@ type VoiceWorkerService struct {
 	fileWatcher   FileWatcherService
 	parserManager ParserManagerService
 	jobQueue      JobQueueService
+	costTracking  *CostTrackingService
 	convexClient  *ConvexClient
 	ctx           context.Context
 }
@@ -154,7 +255,11 @@ func (v *VoiceWorkerService) Initialize(ctx context.Context) error {
 	v.fileWatcher = fileWatcher
 
 	v.parserManager = NewParserManagerServiceImpl(v.convexClient)
-	v.jobQueue = NewJobQueueServiceImpl(v.convexClient, v.parserManager)
+	v.costTracking = NewCostTrackingService(v.convexClient)
+
+	jobQueue := NewJobQueueServiceImpl(v.convexClient, v.parserManager, v.costTracking)
+	jobQueue.SetContext(ctx)
+	v.jobQueue = jobQueue
 
 	return nil
 }
@@ -165,20 +270,52 @@ func (v *VoiceWorkerService) Start() error {
 	return nil
 }
 
-func (v *VoiceWorkerService) Stop() error {
-	// Stop services gracefully
+// Stop gracefully shuts down every service, waiting for in-flight jobs to
+// finish (or be force-cancelled by StopProcessing's own jobGracefulTimeout)
+// up to ctx's deadline. If ctx is cancelled first, Stop returns without
+// waiting further - the job queue's own goroutines keep force-killing
+// stragglers in the background regardless.
+func (v *VoiceWorkerService) Stop(ctx context.Context) error {
 	if v.fileWatcher != nil && v.fileWatcher.IsMonitoring() {
 		v.fileWatcher.StopMonitoring()
 	}
 	if v.jobQueue != nil {
 		v.jobQueue.StopProcessing()
+
+		done := make(chan struct{})
+		go func() {
+			v.jobQueue.WaitForShutdown()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			log.Printf("Warning: shutdown context expired before jobs finished draining")
+		}
+	}
+	if v.costTracking != nil {
+		if err := v.costTracking.Close(); err != nil {
+			log.Printf("Warning: failed to close cost store: %v", err)
+		}
 	}
 	return nil
 }
 
 // Frontend interface methods
 func (v *VoiceWorkerService) SetSelectedFolder(folderId, path string) error {
-	return v.fileWatcher.SetMonitoredFolder(folderId, path)
+	if err := v.fileWatcher.SetMonitoredFolder(folderId, path); err != nil {
+		return err
+	}
+
+	// In distributed mode, advertise that this node can read files under
+	// path directly, so claim/routing can prefer it over a node without
+	// local filesystem access to the monitored folder.
+	if impl, ok := v.jobQueue.(*JobQueueServiceImpl); ok {
+		impl.SetLocalPathPrefixes([]string{path})
+	}
+
+	return nil
 }
 
 func (v *VoiceWorkerService) StartFolderMonitoring() error {
@@ -216,3 +353,73 @@ func (v *VoiceWorkerService) EnableParser(parserId string) error {
 func (v *VoiceWorkerService) DisableParser(parserId string) error {
 	return v.parserManager.DisableParser(parserId)
 }
+
+// GetBudget returns the currently configured cost budget limits.
+func (v *VoiceWorkerService) GetBudget() BudgetConfig {
+	return v.costTracking.GetBudget()
+}
+
+// SetBudget updates the cost budget limits enforced by checkJobBudget.
+func (v *VoiceWorkerService) SetBudget(budget BudgetConfig) error {
+	return v.costTracking.SetBudget(budget)
+}
+
+// DryRunReport summarizes DryRun's estimated cost for every file in the
+// currently monitored folder, broken down by which parser would process
+// it, without queuing or running a single job.
+type DryRunReport struct {
+	FolderID         string             `json:"folderId"`
+	FileCount        int                `json:"fileCount"`
+	EstimatedCostUSD float64            `json:"estimatedCostUsd"`
+	PerParser        map[string]float64 `json:"perParser"`
+}
+
+// DryRun estimates what processing every file currently in the monitored
+// folder would cost, running the pipeline through Parser.EstimateCost only
+// - no job is queued and no parser's Process is ever called. It's meant to
+// answer "what would this folder cost me" before committing to a run,
+// complementing checkJobBudget's per-job admission control with a
+// before-the-fact forecast.
+func (v *VoiceWorkerService) DryRun() (DryRunReport, error) {
+	folderId := v.fileWatcher.GetSelectedFolderID()
+	report := DryRunReport{FolderID: folderId, PerParser: map[string]float64{}}
+
+	result, err := v.convexClient.CallQuery("files:getByFolder", map[string]interface{}{
+		"folderId": folderId,
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to list files for folder %s: %w", folderId, err)
+	}
+
+	list, ok := result.([]interface{})
+	if !ok {
+		return report, fmt.Errorf("invalid files data for folder %s", folderId)
+	}
+
+	for _, item := range list {
+		fileMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		file := fileRecordFromMap(fileMap)
+		report.FileCount++
+
+		parsers, err := v.parserManager.GetApplicableParsers(file.FileType)
+		if err != nil {
+			log.Printf("Warning: failed to get applicable parsers for file %s: %v", file.Path, err)
+			continue
+		}
+
+		for _, parser := range parsers {
+			cost, err := parser.EstimateCost(file.Path)
+			if err != nil {
+				log.Printf("Warning: failed to estimate cost for %s: %v", file.Path, err)
+				continue
+			}
+			report.PerParser[parser.GetID()] += cost
+			report.EstimatedCostUSD += cost
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// structuredLogDir and structuredLogFileName locate the JSON-lines log a
+// log viewer tails, parallel to auditLogDir for session audit events.
+const (
+	structuredLogDir      = "logs"
+	structuredLogFileName = "voiceworker.jsonl"
+	structuredLogRingSize = 1000
+)
+
+// StructuredLogLevel is the severity of a StructuredLogEntry.
+type StructuredLogLevel string
+
+const (
+	StructuredLogLevelInfo  StructuredLogLevel = "info"
+	StructuredLogLevelWarn  StructuredLogLevel = "warn"
+	StructuredLogLevelError StructuredLogLevel = "error"
+)
+
+// Field is a single structured key/value pair attached to a log call, e.g.
+// F("file_path", path) or F("parser_id", parserId).
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F builds a Field, the way the log call sites in this file read like
+// appLog.Error(ctx, "msg", F("file_path", path), F("hash", hash)).
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogEntry is one JSON line written by StructuredLogger, and the
+// shape a log viewer tails and filters.
+type StructuredLogEntry struct {
+	TimestampMs int64                  `json:"timestampMs"`
+	Level       StructuredLogLevel     `json:"level"`
+	Message     string                 `json:"message"`
+	RequestID   string                 `json:"requestId,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+}
+
+// requestIDContextKey is the context.Context key WithRequestID and
+// RequestIDFromContext use to thread a per-file correlation ID through
+// handleFileEvent, Process, and the Convex calls they make.
+type requestIDContextKey struct{}
+
+// NewRequestID mints a correlation ID for one file's trip through the
+// pipeline, the same nanosecond-timestamp scheme LocalJobStore uses to
+// mint IDs without a central counter.
+func NewRequestID() string {
+	return fmt.Sprintf("req_%d", time.Now().UnixNano())
+}
+
+// WithRequestID attaches requestID to ctx, so every appLog call made with
+// the returned context (or one derived from it) is tagged with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached by WithRequestID, or
+// "" if ctx carries none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// StructuredLogger writes structured log entries as JSON lines to disk and
+// keeps a bounded in-memory ring buffer of the most recent ones, so a log
+// viewer can tail recent activity without re-reading the file on every
+// poll.
+type StructuredLogger struct {
+	mutex   sync.Mutex
+	file    *os.File
+	entries []StructuredLogEntry
+	nextIdx int
+}
+
+// appLog is the process-wide StructuredLogger every Info/Warn/Error call
+// writes through, the same singleton pattern as facilityRegistry.
+var appLog = newStructuredLogger()
+
+func newStructuredLogger() *StructuredLogger {
+	if err := os.MkdirAll(structuredLogDir, 0755); err != nil {
+		fmt.Printf("Error creating structured log directory: %v\n", err)
+		return &StructuredLogger{}
+	}
+
+	path := filepath.Join(structuredLogDir, structuredLogFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("Error opening structured log file: %v\n", err)
+		return &StructuredLogger{}
+	}
+
+	return &StructuredLogger{file: file}
+}
+
+func (l *StructuredLogger) write(ctx context.Context, level StructuredLogLevel, msg string, fields []Field) {
+	fieldMap := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Key] = field.Value
+	}
+
+	entry := StructuredLogEntry{
+		TimestampMs: time.Now().UnixMilli(),
+		Level:       level,
+		Message:     msg,
+		RequestID:   RequestIDFromContext(ctx),
+		Fields:      fieldMap,
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if len(l.entries) < structuredLogRingSize {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.nextIdx] = entry
+		l.nextIdx = (l.nextIdx + 1) % structuredLogRingSize
+	}
+
+	if l.file == nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	if _, err := l.file.Write(data); err != nil {
+		fmt.Printf("Error writing structured log entry: %v\n", err)
+	}
+}
+
+// Info logs msg at info level, tagged with ctx's request ID (if any).
+func (l *StructuredLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	l.write(ctx, StructuredLogLevelInfo, msg, fields)
+}
+
+// Warn logs msg at warn level, tagged with ctx's request ID (if any).
+func (l *StructuredLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	l.write(ctx, StructuredLogLevelWarn, msg, fields)
+}
+
+// Error logs msg at error level, tagged with ctx's request ID (if any).
+func (l *StructuredLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	l.write(ctx, StructuredLogLevelError, msg, fields)
+}
+
+// Recent returns buffered structured log entries oldest-first, optionally
+// filtered by level, parser (Fields["parser_id"]), and file path
+// (Fields["file_path"]). Pass "" for any filter to skip it - this backs a
+// log viewer's level/parser/path filters without re-reading the on-disk
+// file on every keystroke.
+func (l *StructuredLogger) Recent(level, parserID, filePath string) []StructuredLogEntry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	ordered := make([]StructuredLogEntry, 0, len(l.entries))
+	if len(l.entries) < structuredLogRingSize {
+		ordered = append(ordered, l.entries...)
+	} else {
+		ordered = append(ordered, l.entries[l.nextIdx:]...)
+		ordered = append(ordered, l.entries[:l.nextIdx]...)
+	}
+
+	result := make([]StructuredLogEntry, 0, len(ordered))
+	for _, entry := range ordered {
+		if level != "" && string(entry.Level) != level {
+			continue
+		}
+		if parserID != "" && fmt.Sprint(entry.Fields["parser_id"]) != parserID {
+			continue
+		}
+		if filePath != "" && fmt.Sprint(entry.Fields["file_path"]) != filePath {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/joho/godotenv"
 )
 
+var transcriptionLog = GetFacility("transcription")
+
 // TranscriptionService handles communication with the transcription API.
 type TranscriptionService struct {
 	client *openai.Client
@@ -28,9 +30,12 @@ func NewTranscriptionService() (*TranscriptionService, error) {
 	return &TranscriptionService{client: client}, nil
 }
 
-// TranscribeAudio sends audio data to the Whisper API for transcription.
-func (s *TranscriptionService) TranscribeAudio(audioData *bytes.Buffer) (string, error) {
-	fmt.Println("Sending audio for transcription...")
+// TranscribeAudio sends audio data to the Whisper API for transcription. ctx
+// governs the request - cancelling it (e.g. because the recording app's
+// Wails OnShutdown fired mid-call) aborts the in-flight HTTP call instead of
+// waiting it out.
+func (s *TranscriptionService) TranscribeAudio(ctx context.Context, audioData *bytes.Buffer) (string, error) {
+	transcriptionLog.Debugln("Sending audio for transcription...")
 
 	req := openai.AudioRequest{
 		Model:    openai.Whisper1,
@@ -38,11 +43,11 @@ func (s *TranscriptionService) TranscribeAudio(audioData *bytes.Buffer) (string,
 		FilePath: "audio.wav", // Sending a WAV file now
 	}
 
-	resp, err := s.client.CreateTranscription(context.Background(), req)
+	resp, err := s.client.CreateTranscription(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("transcription failed: %w", err)
 	}
 
-	fmt.Println("Transcription successful.")
+	transcriptionLog.Debugln("Transcription successful.")
 	return resp.Text, nil
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// TranscriptSegment is a single timestamped span of a Transcript. Backends
+// that report BackendCapabilities.WordTimestamps populate this; backends
+// that only return a flat transcript leave it empty.
+type TranscriptSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// Transcript is the backend-agnostic result of TranscriptionBackend.Transcribe.
+type Transcript struct {
+	Text     string              `json:"text"`
+	Segments []TranscriptSegment `json:"segments,omitempty"`
+	Language string              `json:"language,omitempty"`
+	Duration float64             `json:"duration"`
+}
+
+// BackendCapabilities describes what a TranscriptionBackend supports, so a
+// caller can reason about a provider (e.g. whether it's safe to hand it a
+// 2-hour file) without reaching into its implementation.
+type BackendCapabilities struct {
+	Streaming        bool
+	MaxFileSizeMB    float64 // 0 means no hard cap is enforced by the backend itself
+	SupportedFormats []string
+	WordTimestamps   bool
+	Diarization      bool
+}
+
+// TranscriptionBackendID selects which TranscriptionBackend implementation
+// TranscriptionParserImpl dispatches to, carried in its Convex-managed
+// configuration under the "backend" key so users can switch providers per
+// folder without a code change.
+type TranscriptionBackendID string
+
+const (
+	TranscriptionBackendOpenAIWhisper TranscriptionBackendID = "openai-whisper"
+	TranscriptionBackendWhisperCpp    TranscriptionBackendID = "whisper-cpp"
+	TranscriptionBackendAssemblyAI    TranscriptionBackendID = "assemblyai"
+	TranscriptionBackendDeepgram      TranscriptionBackendID = "deepgram"
+	TranscriptionBackendAzureSpeech   TranscriptionBackendID = "azure-speech"
+)
+
+// TranscriptionBackend abstracts a single transcription provider, the way
+// rclone's Fs interface abstracts a cloud storage backend: TranscriptionParserImpl
+// talks only to this interface, never to a provider's HTTP or CLI details,
+// so adding a provider or switching one per folder is a config change.
+type TranscriptionBackend interface {
+	GetID() TranscriptionBackendID
+	Capabilities() BackendCapabilities
+	Transcribe(ctx context.Context, inputPath string) (Transcript, error)
+	EstimateCost(filePath string) (float64, error)
+}
+
+// NewTranscriptionBackend builds the TranscriptionBackend selected by
+// config["backend"], defaulting to TranscriptionBackendOpenAIWhisper to
+// match TranscriptionParserImpl's behavior from before backends became
+// pluggable. convexClient is threaded through so each backend's cost
+// estimate can reuse probeAudioFileCached's hash-keyed cache; it may be nil,
+// in which case every estimate re-probes.
+func NewTranscriptionBackend(config map[string]interface{}, convexClient *ConvexClient) (TranscriptionBackend, error) {
+	backendID := TranscriptionBackendOpenAIWhisper
+	if raw, ok := config["backend"].(string); ok && raw != "" {
+		backendID = TranscriptionBackendID(raw)
+	}
+
+	switch backendID {
+	case TranscriptionBackendOpenAIWhisper:
+		return newOpenAIWhisperBackend(config, convexClient)
+	case TranscriptionBackendWhisperCpp:
+		return newWhisperCppBackend(config, convexClient)
+	case TranscriptionBackendAssemblyAI:
+		return newAssemblyAIBackend(config, convexClient)
+	case TranscriptionBackendDeepgram:
+		return newDeepgramBackend(config, convexClient)
+	case TranscriptionBackendAzureSpeech:
+		return newAzureSpeechBackend(config, convexClient)
+	default:
+		return nil, fmt.Errorf("unknown transcription backend: %q", backendID)
+	}
+}
+
+// estimateDurationSecondsByFileSize reports filePath's audio duration,
+// shared by every backend's cost model instead of duplicated per backend.
+// It prefers probeAudioFileCached's ffprobe/header-based reading of the
+// file's actual stream metadata (reusing a Convex-cached result by content
+// hash when convexClient is non-nil, so admission control calling this on
+// every job dispatch doesn't re-probe an unchanged file), falling back to
+// the original file-size heuristic (~1MB/minute for compressed speech
+// audio) only when every probe fails.
+func estimateDurationSecondsByFileSize(convexClient *ConvexClient, filePath string) (float64, error) {
+	if probe, err := probeAudioFileCached(convexClient, filePath); err == nil && probe.DurationSeconds > 0 {
+		return probe.DurationSeconds, nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	fileSizeMB := float64(info.Size()) / (1024 * 1024)
+	estimatedMinutes := fileSizeMB
+	return estimatedMinutes * 60, nil
+}
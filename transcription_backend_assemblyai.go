@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ASSEMBLYAI_COST_PER_MINUTE approximates AssemblyAI's pay-as-you-go core
+// transcription rate.
+const ASSEMBLYAI_COST_PER_MINUTE = 0.0062
+
+// assemblyAIPollInterval is how often poll rechecks a submitted
+// transcript's status - AssemblyAI has no synchronous endpoint, so
+// Transcribe has to upload, submit, then poll until done.
+const assemblyAIPollInterval = 3 * time.Second
+
+type assemblyAIBackend struct {
+	apiKey       string
+	convexClient *ConvexClient
+}
+
+func newAssemblyAIBackend(config map[string]interface{}, convexClient *ConvexClient) (*assemblyAIBackend, error) {
+	apiKey, _ := config["apiKey"].(string)
+	if apiKey == "" {
+		return nil, fmt.Errorf("AssemblyAI API key not configured")
+	}
+
+	return &assemblyAIBackend{apiKey: apiKey, convexClient: convexClient}, nil
+}
+
+func (b *assemblyAIBackend) GetID() TranscriptionBackendID {
+	return TranscriptionBackendAssemblyAI
+}
+
+func (b *assemblyAIBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Streaming:        true,
+		MaxFileSizeMB:    5120,
+		SupportedFormats: []string{".mp3", ".wav", ".m4a", ".flac", ".ogg"},
+		WordTimestamps:   true,
+		Diarization:      true,
+	}
+}
+
+func (b *assemblyAIBackend) Transcribe(ctx context.Context, inputPath string) (Transcript, error) {
+	uploadURL, err := b.upload(ctx, inputPath)
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	transcriptID, err := b.submit(ctx, uploadURL)
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	return b.poll(ctx, transcriptID)
+}
+
+func (b *assemblyAIBackend) upload(ctx context.Context, inputPath string) (string, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio file: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "assemblyai:upload", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.assemblyai.com/v2/upload", bytes.NewReader(data))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", b.apiKey)
+		req.Header.Set("Content-Type", "application/octet-stream")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.Unmarshal(body, &uploaded); err != nil {
+		return "", fmt.Errorf("failed to decode upload response: %v", err)
+	}
+	return uploaded.UploadURL, nil
+}
+
+func (b *assemblyAIBackend) submit(ctx context.Context, audioURL string) (string, error) {
+	payload, err := json.Marshal(map[string]interface{}{"audio_url": audioURL})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request payload: %v", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	body, err := httpxDoWithRetry(ctx, "assemblyai:submit", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.assemblyai.com/v2/transcript", bytes.NewReader(payload))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", b.apiKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("submit failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var submitted struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &submitted); err != nil {
+		return "", fmt.Errorf("failed to decode submit response: %v", err)
+	}
+	return submitted.ID, nil
+}
+
+// poll repeatedly fetches transcriptID's status until AssemblyAI reports
+// "completed" or "error", the same plain poll-loop shape
+// advanceScheduledRetries and budgetGateSweeper use elsewhere in this
+// codebase rather than any webhook wiring.
+func (b *assemblyAIBackend) poll(ctx context.Context, transcriptID string) (Transcript, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	url := fmt.Sprintf("https://api.assemblyai.com/v2/transcript/%s", transcriptID)
+
+	for {
+		body, err := httpxDoWithRetry(ctx, "assemblyai:poll", defaultRetryPolicy(), func() (int, []byte, error) {
+			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+			if err != nil {
+				return 0, nil, fmt.Errorf("failed to create request: %v", err)
+			}
+			req.Header.Set("Authorization", b.apiKey)
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return 0, nil, fmt.Errorf("failed to send request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return resp.StatusCode, respBody, fmt.Errorf("poll failed with status %d: %s", resp.StatusCode, string(respBody))
+			}
+			return resp.StatusCode, respBody, nil
+		})
+		if err != nil {
+			return Transcript{}, err
+		}
+
+		var result struct {
+			Status   string  `json:"status"`
+			Text     string  `json:"text"`
+			Error    string  `json:"error"`
+			Language string  `json:"language_code"`
+			Duration float64 `json:"audio_duration"`
+			Words    []struct {
+				Start int    `json:"start"`
+				End   int    `json:"end"`
+				Text  string `json:"text"`
+			} `json:"words"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return Transcript{}, fmt.Errorf("failed to decode poll response: %v", err)
+		}
+
+		switch result.Status {
+		case "completed":
+			segments := make([]TranscriptSegment, 0, len(result.Words))
+			for _, w := range result.Words {
+				segments = append(segments, TranscriptSegment{
+					Start: float64(w.Start) / 1000.0,
+					End:   float64(w.End) / 1000.0,
+					Text:  w.Text,
+				})
+			}
+			return Transcript{
+				Text:     result.Text,
+				Segments: segments,
+				Language: result.Language,
+				Duration: result.Duration,
+			}, nil
+		case "error":
+			return Transcript{}, fmt.Errorf("AssemblyAI transcription failed: %s", result.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Transcript{}, ctx.Err()
+		case <-time.After(assemblyAIPollInterval):
+		}
+	}
+}
+
+func (b *assemblyAIBackend) EstimateCost(filePath string) (float64, error) {
+	estimatedSeconds, err := estimateDurationSecondsByFileSize(b.convexClient, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return (estimatedSeconds / 60.0) * ASSEMBLYAI_COST_PER_MINUTE, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// AZURE_SPEECH_COST_PER_MINUTE approximates Azure AI Speech's standard
+// pay-as-you-go transcription rate.
+const AZURE_SPEECH_COST_PER_MINUTE = 0.0167
+
+type azureSpeechBackend struct {
+	apiKey       string
+	region       string
+	convexClient *ConvexClient
+}
+
+func newAzureSpeechBackend(config map[string]interface{}, convexClient *ConvexClient) (*azureSpeechBackend, error) {
+	apiKey, _ := config["apiKey"].(string)
+	region, _ := config["azureRegion"].(string)
+	if apiKey == "" || region == "" {
+		return nil, fmt.Errorf("Azure Speech API key/region not configured")
+	}
+
+	return &azureSpeechBackend{apiKey: apiKey, region: region, convexClient: convexClient}, nil
+}
+
+func (b *azureSpeechBackend) GetID() TranscriptionBackendID {
+	return TranscriptionBackendAzureSpeech
+}
+
+// Capabilities describes the short-audio REST endpoint Transcribe uses, not
+// Azure's separate long-running batch transcription API - it has no word
+// timestamps or diarization, and a file size cap well under what the batch
+// API would allow.
+func (b *azureSpeechBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Streaming:        false,
+		MaxFileSizeMB:    60,
+		SupportedFormats: []string{".wav"},
+		WordTimestamps:   false,
+		Diarization:      false,
+	}
+}
+
+func (b *azureSpeechBackend) Transcribe(ctx context.Context, inputPath string) (Transcript, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to read audio file: %v", err)
+	}
+
+	url := fmt.Sprintf("https://%s.stt.speech.microsoft.com/speech/recognition/conversation/cognitiveservices/v1?language=en-US", b.region)
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "azure:speech", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Ocp-Apim-Subscription-Key", b.apiKey)
+		req.Header.Set("Content-Type", "audio/wav; codecs=audio/pcm; samplerate=16000")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	var result struct {
+		DisplayText string `json:"DisplayText"`
+		Duration    int64  `json:"Duration"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Transcript{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return Transcript{
+		Text:     result.DisplayText,
+		Duration: float64(result.Duration) / 1e7, // Duration is reported in 100-nanosecond units
+	}, nil
+}
+
+func (b *azureSpeechBackend) EstimateCost(filePath string) (float64, error) {
+	estimatedSeconds, err := estimateDurationSecondsByFileSize(b.convexClient, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return (estimatedSeconds / 60.0) * AZURE_SPEECH_COST_PER_MINUTE, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// DEEPGRAM_COST_PER_MINUTE approximates Deepgram's pay-as-you-go
+// pre-recorded Nova rate.
+const DEEPGRAM_COST_PER_MINUTE = 0.0043
+
+type deepgramBackend struct {
+	apiKey       string
+	model        string
+	convexClient *ConvexClient
+}
+
+func newDeepgramBackend(config map[string]interface{}, convexClient *ConvexClient) (*deepgramBackend, error) {
+	apiKey, _ := config["apiKey"].(string)
+	if apiKey == "" {
+		return nil, fmt.Errorf("Deepgram API key not configured")
+	}
+
+	model, _ := config["model"].(string)
+	if model == "" {
+		model = "nova-2"
+	}
+
+	return &deepgramBackend{apiKey: apiKey, model: model, convexClient: convexClient}, nil
+}
+
+func (b *deepgramBackend) GetID() TranscriptionBackendID {
+	return TranscriptionBackendDeepgram
+}
+
+func (b *deepgramBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Streaming:        true,
+		MaxFileSizeMB:    2048,
+		SupportedFormats: []string{".mp3", ".wav", ".m4a", ".flac", ".ogg"},
+		WordTimestamps:   true,
+		Diarization:      true,
+	}
+}
+
+func (b *deepgramBackend) Transcribe(ctx context.Context, inputPath string) (Transcript, error) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to read audio file: %v", err)
+	}
+
+	url := fmt.Sprintf("https://api.deepgram.com/v1/listen?model=%s&diarize=true", b.model)
+
+	client := &http.Client{Timeout: 15 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "deepgram:listen", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", "Token "+b.apiKey)
+		req.Header.Set("Content-Type", "audio/*")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	var result struct {
+		Metadata struct {
+			Duration float64 `json:"duration"`
+		} `json:"metadata"`
+		Results struct {
+			Channels []struct {
+				Alternatives []struct {
+					Transcript string `json:"transcript"`
+					Words      []struct {
+						Word  string  `json:"word"`
+						Start float64 `json:"start"`
+						End   float64 `json:"end"`
+					} `json:"words"`
+				} `json:"alternatives"`
+			} `json:"channels"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Transcript{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if len(result.Results.Channels) == 0 || len(result.Results.Channels[0].Alternatives) == 0 {
+		return Transcript{}, fmt.Errorf("no transcription alternatives returned")
+	}
+
+	alt := result.Results.Channels[0].Alternatives[0]
+	segments := make([]TranscriptSegment, 0, len(alt.Words))
+	for _, w := range alt.Words {
+		segments = append(segments, TranscriptSegment{Start: w.Start, End: w.End, Text: w.Word})
+	}
+
+	return Transcript{
+		Text:     alt.Transcript,
+		Segments: segments,
+		Duration: result.Metadata.Duration,
+	}, nil
+}
+
+func (b *deepgramBackend) EstimateCost(filePath string) (float64, error) {
+	estimatedSeconds, err := estimateDurationSecondsByFileSize(b.convexClient, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return (estimatedSeconds / 60.0) * DEEPGRAM_COST_PER_MINUTE, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// openAIWhisperBackend transcribes via OpenAI's hosted Whisper endpoint -
+// TranscriptionParserImpl's only behavior before backends became pluggable.
+type openAIWhisperBackend struct {
+	apiKey       string
+	model        string
+	convexClient *ConvexClient
+}
+
+func newOpenAIWhisperBackend(config map[string]interface{}, convexClient *ConvexClient) (*openAIWhisperBackend, error) {
+	apiKey, _ := config["apiKey"].(string)
+	if apiKey == "" {
+		return nil, fmt.Errorf("OpenAI API key not configured")
+	}
+
+	model, _ := config["model"].(string)
+	if model == "" {
+		model = "whisper-1"
+	}
+
+	return &openAIWhisperBackend{apiKey: apiKey, model: model, convexClient: convexClient}, nil
+}
+
+func (b *openAIWhisperBackend) GetID() TranscriptionBackendID {
+	return TranscriptionBackendOpenAIWhisper
+}
+
+// Capabilities mirrors OpenAI's documented Whisper API limits: batch only,
+// a 25MB upload cap, and no diarization or word-level timestamps in the
+// plain "text" response format Transcribe requests.
+func (b *openAIWhisperBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Streaming:        false,
+		MaxFileSizeMB:    25,
+		SupportedFormats: []string{".mp3", ".wav", ".m4a", ".aac", ".flac", ".ogg", ".wma"},
+		WordTimestamps:   false,
+		Diarization:      false,
+	}
+}
+
+func (b *openAIWhisperBackend) Transcribe(ctx context.Context, inputPath string) (Transcript, error) {
+	file, err := os.Open(inputPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to open audio file: %v", err)
+	}
+	defer file.Close()
+
+	var requestBody bytes.Buffer
+	writer := multipart.NewWriter(&requestBody)
+
+	fileWriter, err := writer.CreateFormFile("file", filepath.Base(inputPath))
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to create form file: %v", err)
+	}
+	if _, err := io.Copy(fileWriter, file); err != nil {
+		return Transcript{}, fmt.Errorf("failed to copy file data: %v", err)
+	}
+
+	if err := writer.WriteField("model", b.model); err != nil {
+		return Transcript{}, fmt.Errorf("failed to write model field: %v", err)
+	}
+	if err := writer.WriteField("response_format", "text"); err != nil {
+		return Transcript{}, fmt.Errorf("failed to write response format field: %v", err)
+	}
+
+	writer.Close()
+	requestBodyBytes := requestBody.Bytes()
+	contentType := writer.FormDataContentType()
+
+	// Retried (with backoff + jitter) and circuit-broken so a flaky Whisper
+	// call doesn't burn a job attempt on the first transient 429/5xx.
+	client := &http.Client{Timeout: 30 * time.Minute}
+	body, err := httpxDoWithRetry(ctx, "openai:whisper", defaultRetryPolicy(), func() (int, []byte, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/audio/transcriptions", bytes.NewReader(requestBodyBytes))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+b.apiKey)
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.StatusCode, nil, fmt.Errorf("failed to read response: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, respBody, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		}
+
+		return resp.StatusCode, respBody, nil
+	})
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	duration, _ := estimateDurationSecondsByFileSize(b.convexClient, inputPath)
+	return Transcript{Text: string(body), Duration: duration}, nil
+}
+
+func (b *openAIWhisperBackend) EstimateCost(filePath string) (float64, error) {
+	estimatedSeconds, err := estimateDurationSecondsByFileSize(b.convexClient, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return estimatedSeconds * WHISPER_COST_PER_SECOND, nil
+}
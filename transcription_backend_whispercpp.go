@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// whisperCppBackend shells out to a locally installed whisper.cpp binary
+// instead of calling a hosted API - free to run and keeps audio on-disk, at
+// the cost of needing a model file and compute on the machine running the
+// worker.
+type whisperCppBackend struct {
+	binaryPath   string
+	modelPath    string
+	convexClient *ConvexClient
+}
+
+func newWhisperCppBackend(config map[string]interface{}, convexClient *ConvexClient) (*whisperCppBackend, error) {
+	modelPath, _ := config["whisperModelPath"].(string)
+	if modelPath == "" {
+		return nil, fmt.Errorf("whisper.cpp model path not configured")
+	}
+
+	binaryPath, _ := config["whisperBinaryPath"].(string)
+	if binaryPath == "" {
+		binaryPath = "whisper"
+	}
+
+	return &whisperCppBackend{binaryPath: binaryPath, modelPath: modelPath, convexClient: convexClient}, nil
+}
+
+func (b *whisperCppBackend) GetID() TranscriptionBackendID {
+	return TranscriptionBackendWhisperCpp
+}
+
+// Capabilities assumes a CPU/GPU-local build: no file size cap beyond disk
+// and memory, batch only since whisper.cpp processes a whole file per run,
+// and no diarization or word-level timestamps since neither is requested
+// by the flags Transcribe invokes it with.
+func (b *whisperCppBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Streaming:        false,
+		MaxFileSizeMB:    0,
+		SupportedFormats: []string{".wav"},
+		WordTimestamps:   false,
+		Diarization:      false,
+	}
+}
+
+func (b *whisperCppBackend) Transcribe(ctx context.Context, inputPath string) (Transcript, error) {
+	cmd := exec.CommandContext(ctx, b.binaryPath, "-m", b.modelPath, "-f", inputPath, "-nt", "-otxt", "-of", "-")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return Transcript{}, fmt.Errorf("whisper.cpp exited with error: %v: %s", err, string(exitErr.Stderr))
+		}
+		return Transcript{}, fmt.Errorf("failed to run whisper.cpp: %v", err)
+	}
+
+	duration, _ := estimateDurationSecondsByFileSize(b.convexClient, inputPath)
+	return Transcript{Text: strings.TrimSpace(string(output)), Duration: duration}, nil
+}
+
+// EstimateCost is always zero - whisper.cpp runs on local compute the
+// worker already owns, not a metered API call.
+func (b *whisperCppBackend) EstimateCost(filePath string) (float64, error) {
+	return 0, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var transcriptionChunkLog = GetFacility("transcriptionchunk")
+
+// transcriptionChunkSafetyFactor shrinks the computed chunk duration below
+// the backend's byte budget, since ffmpeg's segment muxer cuts on keyframe
+// boundaries rather than at an exact byte offset - a segment can run a bit
+// over the target duration, and this margin keeps it from tipping over
+// MaxFileSizeMB anyway.
+const transcriptionChunkSafetyFactor = 0.85
+
+// transcribeWithChunking transcribes inputPath via backend, transparently
+// splitting it into time-based segments first when it's over the backend's
+// MaxFileSizeMB and stitching the per-segment transcripts back into one
+// Transcript. Backends with no declared size cap (MaxFileSizeMB == 0, e.g.
+// a local whisper.cpp build with no upload limit) are called directly.
+func transcribeWithChunking(ctx context.Context, backend TranscriptionBackend, inputPath string) (Transcript, error) {
+	caps := backend.Capabilities()
+	if caps.MaxFileSizeMB <= 0 {
+		return backend.Transcribe(ctx, inputPath)
+	}
+
+	info, err := os.Stat(inputPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to stat input file: %v", err)
+	}
+
+	maxBytes := int64(caps.MaxFileSizeMB * 1024 * 1024)
+	if info.Size() <= maxBytes {
+		return backend.Transcribe(ctx, inputPath)
+	}
+
+	chunkPaths, cleanup, err := splitAudioForTranscription(inputPath, info.Size(), maxBytes)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to split oversized file for %s: %v", backend.GetID(), err)
+	}
+	defer cleanup()
+
+	transcriptionChunkLog.Debugf("%s over %.0fMB cap, split into %d chunks: %s\n", backend.GetID(), caps.MaxFileSizeMB, len(chunkPaths), inputPath)
+
+	var textParts []string
+	var segments []TranscriptSegment
+	var language string
+	var totalDuration float64
+
+	for _, chunkPath := range chunkPaths {
+		chunkTranscript, err := backend.Transcribe(ctx, chunkPath)
+		if err != nil {
+			return Transcript{}, fmt.Errorf("failed to transcribe chunk %s: %v", filepath.Base(chunkPath), err)
+		}
+
+		textParts = append(textParts, strings.TrimSpace(chunkTranscript.Text))
+		for _, seg := range chunkTranscript.Segments {
+			segments = append(segments, TranscriptSegment{
+				Start: seg.Start + totalDuration,
+				End:   seg.End + totalDuration,
+				Text:  seg.Text,
+			})
+		}
+		if language == "" {
+			language = chunkTranscript.Language
+		}
+		totalDuration += chunkTranscript.Duration
+	}
+
+	return Transcript{
+		Text:     strings.Join(textParts, " "),
+		Segments: segments,
+		Language: language,
+		Duration: totalDuration,
+	}, nil
+}
+
+// splitAudioForTranscription shells out to ffmpeg's segment muxer to cut
+// inputPath into same-format chunks sized to fit under maxBytes, estimating
+// each chunk's duration from the file's average bytes-per-second (inputSize
+// / probed duration) rather than re-encoding to a target size. Returns the
+// chunk paths in playback order and a cleanup func that removes the temp
+// directory holding them; callers must call cleanup once done with the
+// chunks.
+func splitAudioForTranscription(inputPath string, inputSize, maxBytes int64) (chunkPaths []string, cleanup func(), err error) {
+	probe, err := probeAudioFile(inputPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to probe duration: %w", err)
+	}
+	if probe.DurationSeconds <= 0 {
+		return nil, nil, fmt.Errorf("probed duration is zero for %s", inputPath)
+	}
+
+	bytesPerSecond := float64(inputSize) / probe.DurationSeconds
+	chunkSeconds := (float64(maxBytes) * transcriptionChunkSafetyFactor) / bytesPerSecond
+	if chunkSeconds <= 0 {
+		return nil, nil, fmt.Errorf("computed non-positive chunk duration for %s", inputPath)
+	}
+
+	tempDir, err := os.MkdirTemp("", "voiceworker-chunks-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(tempDir); err != nil {
+			transcriptionChunkLog.Debugf("failed to remove chunk temp dir %s: %v\n", tempDir, err)
+		}
+	}
+
+	ext := filepath.Ext(inputPath)
+	pattern := filepath.Join(tempDir, "chunk_%04d"+ext)
+
+	cmd := exec.Command("ffmpeg",
+		"-i", inputPath,
+		"-f", "segment",
+		"-segment_time", strconv.FormatFloat(chunkSeconds, 'f', 2, 64),
+		"-c", "copy",
+		"-reset_timestamps", "1",
+		pattern,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("ffmpeg segment split failed: %w (%s)", err, string(out))
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to read chunk dir: %w", err)
+	}
+
+	var chunks []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		chunks = append(chunks, filepath.Join(tempDir, entry.Name()))
+	}
+	if len(chunks) == 0 {
+		cleanup()
+		return nil, nil, fmt.Errorf("ffmpeg produced no chunks for %s", inputPath)
+	}
+
+	// os.ReadDir already returns entries sorted by name, and chunk_%04d
+	// zero-pads to keep that sort in playback order.
+	return chunks, cleanup, nil
+}